@@ -0,0 +1,25 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE subscriptions
+ADD COLUMN paused_at DATETIME;
+
+ALTER TABLE subscriptions
+ADD COLUMN paused_until DATETIME;
+
+CREATE TABLE user_settings (
+  user_id TEXT PRIMARY KEY,
+  vacation_at DATETIME,
+  vacation_until DATETIME
+);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS user_settings;
+
+ALTER TABLE subscriptions
+DROP COLUMN paused_until;
+
+ALTER TABLE subscriptions
+DROP COLUMN paused_at;
+-- +goose StatementEnd
@@ -0,0 +1,23 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE subscriptions
+ADD COLUMN exclude_words TEXT NOT NULL DEFAULT '';
+
+ALTER TABLE subscriptions
+ADD COLUMN require_words TEXT NOT NULL DEFAULT '';
+
+ALTER TABLE subscriptions
+ADD COLUMN filter_category BOOLEAN NOT NULL DEFAULT FALSE;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE subscriptions
+DROP COLUMN filter_category;
+
+ALTER TABLE subscriptions
+DROP COLUMN require_words;
+
+ALTER TABLE subscriptions
+DROP COLUMN exclude_words;
+-- +goose StatementEnd
@@ -0,0 +1,18 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE webhooks (
+  id TEXT PRIMARY KEY,
+  user_id TEXT NOT NULL,
+  subscription_id TEXT NOT NULL DEFAULT '',
+  url TEXT NOT NULL,
+  secret TEXT NOT NULL,
+  created_at DATETIME NOT NULL
+);
+CREATE UNIQUE INDEX idx_user_id_subscription_id ON webhooks(user_id, subscription_id);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX IF EXISTS idx_user_id_subscription_id;
+DROP TABLE IF EXISTS webhooks;
+-- +goose StatementEnd
@@ -0,0 +1,35 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE user_settings
+ADD COLUMN email TEXT;
+
+ALTER TABLE user_settings
+ADD COLUMN pending_email TEXT;
+
+ALTER TABLE user_settings
+ADD COLUMN email_code TEXT;
+
+ALTER TABLE user_settings
+ADD COLUMN email_code_expires_at DATETIME;
+
+ALTER TABLE user_settings
+ADD COLUMN email_code_attempts INTEGER NOT NULL DEFAULT 0;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE user_settings
+DROP COLUMN email_code_attempts;
+
+ALTER TABLE user_settings
+DROP COLUMN email_code_expires_at;
+
+ALTER TABLE user_settings
+DROP COLUMN email_code;
+
+ALTER TABLE user_settings
+DROP COLUMN pending_email;
+
+ALTER TABLE user_settings
+DROP COLUMN email;
+-- +goose StatementEnd
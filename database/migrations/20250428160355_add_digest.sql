@@ -0,0 +1,35 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE subscriptions
+ADD COLUMN delivery TEXT NOT NULL DEFAULT '';
+
+ALTER TABLE user_settings
+ADD COLUMN delivery TEXT NOT NULL DEFAULT 'instant';
+
+ALTER TABLE user_settings
+ADD COLUMN digest_hour INTEGER NOT NULL DEFAULT 9;
+
+CREATE TABLE queued_items (
+  id TEXT PRIMARY KEY,
+  subscription_id TEXT NOT NULL,
+  goodwill_id INTEGER NOT NULL,
+  item TEXT NOT NULL,
+  created_at DATETIME NOT NULL
+);
+CREATE INDEX idx_queued_items_subscription_id ON queued_items(subscription_id);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX IF EXISTS idx_queued_items_subscription_id;
+DROP TABLE IF EXISTS queued_items;
+
+ALTER TABLE user_settings
+DROP COLUMN digest_hour;
+
+ALTER TABLE user_settings
+DROP COLUMN delivery;
+
+ALTER TABLE subscriptions
+DROP COLUMN delivery;
+-- +goose StatementEnd
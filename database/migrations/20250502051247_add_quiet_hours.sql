@@ -0,0 +1,29 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE user_settings
+ADD COLUMN timezone TEXT NOT NULL DEFAULT '';
+
+ALTER TABLE user_settings
+ADD COLUMN quiet_start INTEGER;
+
+ALTER TABLE user_settings
+ADD COLUMN quiet_end INTEGER;
+
+ALTER TABLE user_settings
+ADD COLUMN ending_soon_in_quiet_hours BOOLEAN NOT NULL DEFAULT FALSE;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE user_settings
+DROP COLUMN ending_soon_in_quiet_hours;
+
+ALTER TABLE user_settings
+DROP COLUMN quiet_end;
+
+ALTER TABLE user_settings
+DROP COLUMN quiet_start;
+
+ALTER TABLE user_settings
+DROP COLUMN timezone;
+-- +goose StatementEnd
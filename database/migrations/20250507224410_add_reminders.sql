@@ -0,0 +1,58 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE subscriptions
+ADD COLUMN reminders TEXT NOT NULL DEFAULT '10';
+
+UPDATE subscriptions
+SET reminders = CAST(notify_minutes AS TEXT);
+
+CREATE TABLE item_reminders (
+  item_id TEXT NOT NULL,
+  minutes INTEGER NOT NULL,
+  sent_at DATETIME NOT NULL,
+  PRIMARY KEY (item_id, minutes)
+);
+
+INSERT INTO item_reminders (item_id, minutes, sent_at)
+SELECT i.id, s.notify_minutes, CURRENT_TIMESTAMP
+FROM items i
+JOIN subscriptions s ON i.subscription_id = s.id
+WHERE i.sent_final = TRUE;
+
+ALTER TABLE subscriptions
+DROP COLUMN notify_minutes;
+
+DROP INDEX IF EXISTS idx_ends_at_sent_final;
+
+ALTER TABLE items
+DROP COLUMN sent_final;
+
+CREATE INDEX idx_ends_at ON items(ends_at);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX IF EXISTS idx_ends_at;
+
+ALTER TABLE items
+ADD COLUMN sent_final BOOLEAN NOT NULL DEFAULT FALSE;
+
+CREATE INDEX idx_ends_at_sent_final ON items(ends_at, sent_final);
+
+UPDATE items
+SET sent_final = TRUE
+WHERE id IN (SELECT item_id FROM item_reminders);
+
+ALTER TABLE subscriptions
+ADD COLUMN notify_minutes INTEGER NOT NULL DEFAULT 10;
+
+-- the smallest reminder is last
+UPDATE subscriptions
+SET notify_minutes = CAST(replace(reminders, rtrim(reminders, '0123456789'), '') AS INTEGER)
+WHERE reminders != '';
+
+DROP TABLE IF EXISTS item_reminders;
+
+ALTER TABLE subscriptions
+DROP COLUMN reminders;
+-- +goose StatementEnd
@@ -0,0 +1,20 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE watched_items (
+  id TEXT PRIMARY KEY,
+  user_id TEXT NOT NULL,
+  goodwill_id INTEGER NOT NULL,
+  title TEXT NOT NULL,
+  ends_at DATETIME NOT NULL,
+  reminders TEXT NOT NULL DEFAULT '10',
+  created_at DATETIME NOT NULL,
+  UNIQUE (user_id, goodwill_id)
+);
+CREATE INDEX idx_watched_items_ends_at ON watched_items(ends_at);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX IF EXISTS idx_watched_items_ends_at;
+DROP TABLE IF EXISTS watched_items;
+-- +goose StatementEnd
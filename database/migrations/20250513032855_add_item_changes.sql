@@ -0,0 +1,70 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE items
+ADD COLUMN current_price REAL NOT NULL DEFAULT 0;
+
+ALTER TABLE items
+ADD COLUMN num_bids INTEGER NOT NULL DEFAULT 0;
+
+ALTER TABLE items
+ADD COLUMN buy_now_price REAL NOT NULL DEFAULT 0;
+
+ALTER TABLE items
+ADD COLUMN checked_at DATETIME;
+
+ALTER TABLE watched_items
+ADD COLUMN current_price REAL NOT NULL DEFAULT 0;
+
+ALTER TABLE watched_items
+ADD COLUMN num_bids INTEGER NOT NULL DEFAULT 0;
+
+ALTER TABLE watched_items
+ADD COLUMN buy_now_price REAL NOT NULL DEFAULT 0;
+
+ALTER TABLE watched_items
+ADD COLUMN checked_at DATETIME;
+
+ALTER TABLE user_settings
+ADD COLUMN bid_jump INTEGER NOT NULL DEFAULT 0;
+
+CREATE TABLE alert_messages (
+  user_id TEXT NOT NULL,
+  goodwill_id INTEGER NOT NULL,
+  channel_id TEXT NOT NULL,
+  message_id TEXT NOT NULL,
+  created_at DATETIME NOT NULL,
+  PRIMARY KEY (user_id, goodwill_id)
+);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS alert_messages;
+
+ALTER TABLE user_settings
+DROP COLUMN bid_jump;
+
+ALTER TABLE watched_items
+DROP COLUMN checked_at;
+
+ALTER TABLE watched_items
+DROP COLUMN buy_now_price;
+
+ALTER TABLE watched_items
+DROP COLUMN num_bids;
+
+ALTER TABLE watched_items
+DROP COLUMN current_price;
+
+ALTER TABLE items
+DROP COLUMN checked_at;
+
+ALTER TABLE items
+DROP COLUMN buy_now_price;
+
+ALTER TABLE items
+DROP COLUMN num_bids;
+
+ALTER TABLE items
+DROP COLUMN current_price;
+-- +goose StatementEnd
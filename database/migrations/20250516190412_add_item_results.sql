@@ -0,0 +1,31 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE item_results (
+  goodwill_id INTEGER PRIMARY KEY,
+  title TEXT NOT NULL,
+  final_price REAL NOT NULL,
+  num_bids INTEGER NOT NULL,
+  ended_at DATETIME NOT NULL,
+  created_at DATETIME NOT NULL
+);
+
+CREATE TABLE item_result_failures (
+  goodwill_id INTEGER PRIMARY KEY,
+  attempts INTEGER NOT NULL,
+  last_error TEXT NOT NULL,
+  final BOOLEAN NOT NULL,
+  next_attempt_at DATETIME NOT NULL
+);
+
+ALTER TABLE user_settings
+ADD COLUMN sold_reports BOOLEAN NOT NULL DEFAULT FALSE;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE user_settings
+DROP COLUMN sold_reports;
+
+DROP TABLE IF EXISTS item_result_failures;
+DROP TABLE IF EXISTS item_results;
+-- +goose StatementEnd
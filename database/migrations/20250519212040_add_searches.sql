@@ -0,0 +1,23 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE searches (
+  id TEXT PRIMARY KEY,
+  user_id TEXT NOT NULL,
+  term TEXT NOT NULL,
+  min_price INTEGER,
+  max_price INTEGER,
+  category_id INTEGER,
+  category_level INTEGER,
+  exclude_words TEXT NOT NULL DEFAULT '',
+  require_words TEXT NOT NULL DEFAULT '',
+  filter_category BOOLEAN NOT NULL DEFAULT FALSE,
+  descending BOOLEAN NOT NULL DEFAULT TRUE,
+  results TEXT,
+  created_at DATETIME NOT NULL
+);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS searches;
+-- +goose StatementEnd
@@ -0,0 +1,27 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE subscriptions
+ADD COLUMN priority BOOLEAN NOT NULL DEFAULT FALSE;
+
+ALTER TABLE subscriptions
+ADD COLUMN poll_interval INTEGER NOT NULL DEFAULT 300;
+
+ALTER TABLE subscriptions
+ADD COLUMN next_poll_at DATETIME;
+
+CREATE INDEX idx_next_poll_at ON subscriptions(next_poll_at);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX IF EXISTS idx_next_poll_at;
+
+ALTER TABLE subscriptions
+DROP COLUMN next_poll_at;
+
+ALTER TABLE subscriptions
+DROP COLUMN poll_interval;
+
+ALTER TABLE subscriptions
+DROP COLUMN priority;
+-- +goose StatementEnd
@@ -0,0 +1,26 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE outbox_messages (
+  id TEXT PRIMARY KEY,
+  user_id TEXT NOT NULL,
+  subscription_id TEXT NOT NULL DEFAULT '',
+  kind TEXT NOT NULL,
+  notification TEXT NOT NULL,
+  sink TEXT NOT NULL,
+  target TEXT NOT NULL DEFAULT '',
+  status TEXT NOT NULL DEFAULT 'pending',
+  attempts INTEGER NOT NULL DEFAULT 0,
+  last_error TEXT NOT NULL DEFAULT '',
+  next_attempt_at DATETIME NOT NULL,
+  created_at DATETIME NOT NULL,
+  sent_at DATETIME
+);
+
+CREATE INDEX idx_outbox_messages_status_next_attempt_at ON outbox_messages(status, next_attempt_at);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX IF EXISTS idx_outbox_messages_status_next_attempt_at;
+DROP TABLE IF EXISTS outbox_messages;
+-- +goose StatementEnd
@@ -0,0 +1,12 @@
+-- name: CreateAlertMessage :exec
+INSERT INTO alert_messages (user_id, goodwill_id, channel_id, message_id, created_at)
+VALUES (?, ?, ?, ?, CURRENT_TIMESTAMP)
+ON CONFLICT (user_id, goodwill_id) DO NOTHING;
+
+-- name: FindAlertMessage :one
+SELECT * FROM alert_messages
+WHERE user_id = ? AND goodwill_id = ?;
+
+-- name: DeleteExpiredAlertMessages :exec
+DELETE FROM alert_messages
+WHERE created_at < datetime('now', '-30 days');
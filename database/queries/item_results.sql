@@ -0,0 +1,37 @@
+-- name: FindEndedItemsWithoutResult :many
+SELECT i.goodwill_id, CAST(COALESCE(MAX(f.attempts), 0) AS INTEGER) AS attempts
+FROM items i
+JOIN subscriptions s ON i.subscription_id = s.id
+JOIN alert_messages am ON am.user_id = s.user_id AND am.goodwill_id = i.goodwill_id
+LEFT JOIN item_result_failures f ON f.goodwill_id = i.goodwill_id
+WHERE i.ends_at < datetime('now', '-5 minutes')
+  AND s.user_id IN (SELECT user_id FROM user_settings WHERE sold_reports = TRUE)
+  AND i.goodwill_id NOT IN (SELECT goodwill_id FROM item_results)
+  AND (f.goodwill_id IS NULL OR (NOT f.final AND f.next_attempt_at <= CURRENT_TIMESTAMP))
+GROUP BY i.goodwill_id
+ORDER BY MIN(i.ends_at)
+LIMIT 50;
+
+-- name: CreateItemResult :one
+INSERT INTO item_results (goodwill_id, title, final_price, num_bids, ended_at, created_at)
+VALUES (?, ?, ?, ?, ?, CURRENT_TIMESTAMP)
+ON CONFLICT (goodwill_id) DO UPDATE
+SET title = excluded.title, final_price = excluded.final_price, num_bids = excluded.num_bids, ended_at = excluded.ended_at
+RETURNING *;
+
+-- name: SetItemResultFailed :exec
+INSERT INTO item_result_failures (goodwill_id, attempts, last_error, final, next_attempt_at)
+VALUES (?, 1, ?, ?, ?)
+ON CONFLICT (goodwill_id) DO UPDATE
+SET attempts = attempts + 1, last_error = excluded.last_error, final = excluded.final, next_attempt_at = excluded.next_attempt_at;
+
+-- name: DeleteOrphanedItemResultFailures :exec
+DELETE FROM item_result_failures
+WHERE goodwill_id NOT IN (SELECT goodwill_id FROM items);
+
+-- name: FindSoldReportSubscriptions :many
+SELECT DISTINCT s.* FROM subscriptions s
+JOIN items i ON i.subscription_id = s.id
+JOIN alert_messages am ON am.user_id = s.user_id AND am.goodwill_id = i.goodwill_id
+WHERE i.goodwill_id = ?
+  AND s.user_id IN (SELECT user_id FROM user_settings WHERE sold_reports = TRUE);
@@ -1,30 +1,64 @@
 -- name: CreateItem :one
-INSERT INTO items (id, subscription_id, goodwill_id, created_at, started_at, ends_at)
-VALUES (?, ?, ?, CURRENT_TIMESTAMP, ?, ?)
+INSERT INTO items (id, subscription_id, goodwill_id, created_at, started_at, ends_at, current_price, num_bids, buy_now_price, checked_at)
+VALUES (?, ?, ?, CURRENT_TIMESTAMP, ?, ?, ?, ?, ?, CURRENT_TIMESTAMP)
 RETURNING *;
 
--- name: IsItemTracked :one
-SELECT EXISTS (
-  SELECT 1
-  FROM items
-  WHERE subscription_id = ? AND goodwill_id = ?
-) AS is_tracked;
+-- name: FindTrackedGoodwillIDs :many
+SELECT goodwill_id
+FROM items
+WHERE subscription_id = ? AND goodwill_id IN (sqlc.slice('goodwill_ids'));
 
--- name: FindItemsEndingSoon :many
-SELECT i.* FROM items i
+-- name: FindDueReminders :many
+SELECT sqlc.embed(i), CAST(r.value AS INTEGER) AS minutes
+FROM items i
 JOIN subscriptions s ON i.subscription_id = s.id
-WHERE i.ends_at < datetime('now', '+' || s.notify_minutes || ' minutes') AND i.sent_final = FALSE
+JOIN json_each('[' || s.reminders || ']') r
+WHERE i.ends_at < datetime('now', '+' || r.value || ' minutes')
+  AND i.ends_at > CURRENT_TIMESTAMP
+  AND NOT EXISTS (SELECT 1 FROM item_reminders ir WHERE ir.item_id = i.id AND ir.minutes = r.value)
+  AND s.paused_at IS NULL
+  AND s.user_id NOT IN (SELECT user_id FROM user_settings WHERE vacation_at IS NOT NULL)
 LIMIT 100;
 
--- name: SetItemSentFinal :exec
+-- name: SetItemReminderSent :exec
+INSERT INTO item_reminders (item_id, minutes, sent_at)
+VALUES (?, ?, CURRENT_TIMESTAMP)
+ON CONFLICT (item_id, minutes) DO NOTHING;
+
+-- name: FindItemsToRefresh :many
+SELECT sqlc.embed(i), sqlc.embed(s)
+FROM items i
+JOIN subscriptions s ON i.subscription_id = s.id
+JOIN alert_messages am ON am.user_id = s.user_id AND am.goodwill_id = i.goodwill_id
+WHERE (i.checked_at IS NULL OR i.checked_at < datetime('now', '-15 minutes'))
+  AND i.ends_at > CURRENT_TIMESTAMP
+  AND s.paused_at IS NULL
+  AND s.user_id NOT IN (SELECT user_id FROM user_settings WHERE vacation_at IS NOT NULL)
+ORDER BY i.checked_at, i.goodwill_id
+LIMIT 100;
+
+-- name: SetItemChecked :exec
 UPDATE items
-SET sent_final = TRUE
-WHERE id IN (sqlc.slice('ids'));
+SET current_price = ?, num_bids = ?, buy_now_price = ?, checked_at = CURRENT_TIMESTAMP
+WHERE id = ?;
 
 -- name: DeleteExpiredItems :exec
 DELETE FROM items
-WHERE ends_at < datetime('now', '-1 day');
+WHERE ends_at < datetime('now', '-1 day')
+  AND (goodwill_id IN (SELECT goodwill_id FROM item_results)
+    OR goodwill_id IN (SELECT goodwill_id FROM item_result_failures WHERE final)
+    OR goodwill_id NOT IN (
+      SELECT am.goodwill_id FROM alert_messages am
+      JOIN user_settings us ON us.user_id = am.user_id
+      WHERE us.sold_reports = TRUE
+    )
+    OR ends_at < datetime('now', '-7 days'));
 
 -- name: DeleteItemsInSubscriptions :exec
 DELETE FROM items
 WHERE subscription_id IN (sqlc.slice('ids'));
+
+-- name: DeleteOrphanedItemReminders :exec
+DELETE FROM item_reminders
+WHERE item_id NOT IN (SELECT id FROM items)
+  AND item_id NOT IN (SELECT id FROM watched_items);
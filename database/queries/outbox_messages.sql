@@ -0,0 +1,32 @@
+-- name: CreateOutboxMessage :exec
+INSERT INTO outbox_messages (id, user_id, subscription_id, kind, notification, sink, target, next_attempt_at, created_at)
+VALUES (?, ?, ?, ?, ?, ?, ?, COALESCE(sqlc.narg('next_attempt_at'), CURRENT_TIMESTAMP), CURRENT_TIMESTAMP);
+
+-- name: FindDueOutboxMessages :many
+SELECT * FROM outbox_messages
+WHERE status = 'pending' AND next_attempt_at <= CURRENT_TIMESTAMP
+ORDER BY created_at
+LIMIT 50;
+
+-- name: SetOutboxMessageSent :exec
+UPDATE outbox_messages
+SET status = 'sent', attempts = attempts + 1, last_error = '', sent_at = CURRENT_TIMESTAMP
+WHERE id = ?;
+
+-- name: SetOutboxMessageFailed :exec
+UPDATE outbox_messages
+SET attempts = attempts + 1, last_error = ?, next_attempt_at = ?
+WHERE id = ?;
+
+-- name: SetOutboxMessageDead :exec
+UPDATE outbox_messages
+SET status = 'dead', attempts = attempts + 1, last_error = ?
+WHERE id = ?;
+
+-- name: DeleteOutboxMessagesInSubscriptions :exec
+DELETE FROM outbox_messages
+WHERE status = 'pending' AND subscription_id IN (sqlc.slice('ids'));
+
+-- name: DeleteExpiredOutboxMessages :exec
+DELETE FROM outbox_messages
+WHERE status != 'pending' AND created_at < datetime('now', '-7 days');
@@ -0,0 +1,22 @@
+-- name: QueueItem :exec
+INSERT INTO queued_items (id, subscription_id, goodwill_id, item, created_at)
+VALUES (?, ?, ?, ?, CURRENT_TIMESTAMP);
+
+-- name: FindQueuedItems :many
+SELECT * FROM queued_items
+WHERE subscription_id = ?
+ORDER BY created_at;
+
+-- name: FindSubscriptionsWithQueuedItems :many
+SELECT * FROM subscriptions
+WHERE id IN (SELECT subscription_id FROM queued_items)
+  AND paused_at IS NULL
+  AND user_id NOT IN (SELECT user_id FROM user_settings WHERE vacation_at IS NOT NULL);
+
+-- name: DeleteQueuedItems :exec
+DELETE FROM queued_items
+WHERE id IN (sqlc.slice('ids'));
+
+-- name: DeleteQueuedItemsInSubscriptions :exec
+DELETE FROM queued_items
+WHERE subscription_id IN (sqlc.slice('ids'));
@@ -0,0 +1,17 @@
+-- name: CreateSearch :one
+INSERT INTO searches (id, user_id, term, min_price, max_price, category_id, category_level, exclude_words, require_words, filter_category, descending, created_at)
+VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, CURRENT_TIMESTAMP)
+RETURNING *;
+
+-- name: FindUserSearch :one
+SELECT * FROM searches
+WHERE id = ? AND user_id = ?;
+
+-- name: SetSearchResults :exec
+UPDATE searches
+SET results = ?
+WHERE id = ?;
+
+-- name: DeleteExpiredSearches :exec
+DELETE FROM searches
+WHERE created_at < datetime('now', '-1 day');
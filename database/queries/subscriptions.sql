@@ -1,6 +1,6 @@
 -- name: CreateSubscription :one
-INSERT INTO subscriptions (id, user_id, term, last_notified_at, min_price, max_price, notify_minutes)
-VALUES (?, ?, ?, 0, ?, ?, ?)
+INSERT INTO subscriptions (id, user_id, term, last_notified_at, min_price, max_price, reminders, category_id, category_level, exclude_words, require_words, filter_category)
+VALUES (?, ?, ?, 0, ?, ?, ?, ?, ?, ?, ?, ?)
 RETURNING *;
 
 -- name: FindSubscription :one
@@ -17,10 +17,55 @@ WHERE user_id = ? AND id IN (sqlc.slice('ids'));
 
 -- name: FindSubscriptionsToNotify :many
 SELECT * FROM subscriptions
-WHERE last_notified_at < datetime('now', '-5 minutes')
+WHERE (next_poll_at IS NULL OR next_poll_at <= CURRENT_TIMESTAMP)
+  AND paused_at IS NULL
+  AND user_id NOT IN (SELECT user_id FROM user_settings WHERE vacation_at IS NOT NULL)
+ORDER BY next_poll_at
 LIMIT 100;
 
+-- name: SetSubscriptionNextPoll :exec
+UPDATE subscriptions
+SET poll_interval = ?, next_poll_at = ?
+WHERE id = ?;
+
+-- name: SetSubscriptionPriority :exec
+UPDATE subscriptions
+SET priority = ?, poll_interval = ?, next_poll_at = NULL
+WHERE id = ? AND user_id = ?;
+
 -- name: SetSubscriptionLastNotifiedAt :exec
 UPDATE subscriptions
 SET last_notified_at = CURRENT_TIMESTAMP
 WHERE id = ?;
+
+-- name: UpdateSubscription :one
+UPDATE subscriptions
+SET term = ?, min_price = ?, max_price = ?, reminders = ?
+WHERE id = ? AND user_id = ?
+RETURNING *;
+
+-- name: PauseUserSubscriptions :exec
+UPDATE subscriptions
+SET paused_at = CURRENT_TIMESTAMP, paused_until = ?
+WHERE user_id = ? AND id IN (sqlc.slice('ids'));
+
+-- name: ResumeSubscription :exec
+UPDATE subscriptions
+SET paused_at = NULL, paused_until = NULL
+WHERE id = ?;
+
+-- name: FindSubscriptionsToResume :many
+SELECT * FROM subscriptions
+WHERE paused_until IS NOT NULL AND paused_until <= CURRENT_TIMESTAMP
+LIMIT 100;
+
+-- name: UpdateSubscriptionFilters :one
+UPDATE subscriptions
+SET exclude_words = ?, require_words = ?, filter_category = ?
+WHERE id = ? AND user_id = ?
+RETURNING *;
+
+-- name: SetSubscriptionDelivery :exec
+UPDATE subscriptions
+SET delivery = ?
+WHERE id = ? AND user_id = ?;
@@ -0,0 +1,53 @@
+-- name: FindUserSettings :one
+SELECT * FROM user_settings
+WHERE user_id = ?;
+
+-- name: StartUserVacation :exec
+INSERT INTO user_settings (user_id, vacation_at, vacation_until)
+VALUES (?, CURRENT_TIMESTAMP, ?)
+ON CONFLICT (user_id) DO UPDATE
+SET vacation_at = excluded.vacation_at, vacation_until = excluded.vacation_until;
+
+-- name: EndUserVacation :exec
+UPDATE user_settings
+SET vacation_at = NULL, vacation_until = NULL
+WHERE user_id = ?;
+
+-- name: FindExpiredVacations :many
+SELECT * FROM user_settings
+WHERE vacation_until IS NOT NULL AND vacation_until <= CURRENT_TIMESTAMP;
+
+-- name: StartUserEmailVerification :exec
+INSERT INTO user_settings (user_id, pending_email, email_code, email_code_expires_at, email_code_attempts)
+VALUES (?, ?, ?, ?, 0)
+ON CONFLICT (user_id) DO UPDATE
+SET pending_email = excluded.pending_email, email_code = excluded.email_code, email_code_expires_at = excluded.email_code_expires_at, email_code_attempts = 0;
+
+-- name: AddUserEmailCodeAttempt :one
+UPDATE user_settings
+SET email_code_attempts = email_code_attempts + 1
+WHERE user_id = ?
+RETURNING *;
+
+-- name: ConfirmUserEmail :exec
+UPDATE user_settings
+SET email = pending_email, pending_email = NULL, email_code = NULL, email_code_expires_at = NULL, email_code_attempts = 0
+WHERE user_id = ?;
+
+-- name: RemoveUserEmail :exec
+UPDATE user_settings
+SET email = NULL, pending_email = NULL, email_code = NULL
+WHERE user_id = ?;
+
+-- name: SetUserDelivery :exec
+INSERT INTO user_settings (user_id, delivery, digest_hour)
+VALUES (?, ?, ?)
+ON CONFLICT (user_id) DO UPDATE
+SET delivery = excluded.delivery, digest_hour = excluded.digest_hour;
+
+-- name: SetUserPreferences :exec
+INSERT INTO user_settings (user_id, timezone, quiet_start, quiet_end, ending_soon_in_quiet_hours, bid_jump, sold_reports)
+VALUES (?, ?, ?, ?, ?, ?, ?)
+ON CONFLICT (user_id) DO UPDATE
+SET timezone = excluded.timezone, quiet_start = excluded.quiet_start, quiet_end = excluded.quiet_end, ending_soon_in_quiet_hours = excluded.ending_soon_in_quiet_hours,
+  bid_jump = excluded.bid_jump, sold_reports = excluded.sold_reports;
@@ -0,0 +1,43 @@
+-- name: WatchItem :one
+INSERT INTO watched_items (id, user_id, goodwill_id, title, ends_at, reminders, created_at, current_price, num_bids, buy_now_price, checked_at)
+VALUES (?, ?, ?, ?, ?, ?, CURRENT_TIMESTAMP, ?, ?, ?, CURRENT_TIMESTAMP)
+ON CONFLICT (user_id, goodwill_id) DO UPDATE
+SET title = excluded.title, ends_at = excluded.ends_at, reminders = excluded.reminders,
+  current_price = excluded.current_price, num_bids = excluded.num_bids, buy_now_price = excluded.buy_now_price, checked_at = excluded.checked_at
+RETURNING *;
+
+-- name: FindUserWatchedItems :many
+SELECT * FROM watched_items
+WHERE user_id = ?
+ORDER BY ends_at;
+
+-- name: DeleteUserWatchedItems :exec
+DELETE FROM watched_items
+WHERE user_id = ? AND id IN (sqlc.slice('ids'));
+
+-- name: FindDueWatchedReminders :many
+SELECT sqlc.embed(w), CAST(r.value AS INTEGER) AS minutes
+FROM watched_items w
+JOIN json_each('[' || w.reminders || ']') r
+WHERE w.ends_at < datetime('now', '+' || r.value || ' minutes')
+  AND w.ends_at > CURRENT_TIMESTAMP
+  AND NOT EXISTS (SELECT 1 FROM item_reminders ir WHERE ir.item_id = w.id AND ir.minutes = r.value)
+  AND w.user_id NOT IN (SELECT user_id FROM user_settings WHERE vacation_at IS NOT NULL)
+LIMIT 100;
+
+-- name: FindWatchedItemsToRefresh :many
+SELECT * FROM watched_items
+WHERE (checked_at IS NULL OR checked_at < datetime('now', '-15 minutes'))
+  AND ends_at > CURRENT_TIMESTAMP
+  AND user_id NOT IN (SELECT user_id FROM user_settings WHERE vacation_at IS NOT NULL)
+ORDER BY checked_at
+LIMIT 50;
+
+-- name: SetWatchedItemChecked :exec
+UPDATE watched_items
+SET current_price = ?, num_bids = ?, buy_now_price = ?, checked_at = CURRENT_TIMESTAMP
+WHERE id = ?;
+
+-- name: DeleteExpiredWatchedItems :exec
+DELETE FROM watched_items
+WHERE ends_at < datetime('now', '-1 day');
@@ -0,0 +1,26 @@
+-- name: UpsertWebhook :one
+INSERT INTO webhooks (id, user_id, subscription_id, url, secret, created_at)
+VALUES (?, ?, ?, ?, ?, CURRENT_TIMESTAMP)
+ON CONFLICT (user_id, subscription_id) DO UPDATE
+SET url = excluded.url, secret = excluded.secret
+RETURNING *;
+
+-- name: FindWebhook :one
+SELECT * FROM webhooks
+WHERE id = ?;
+
+-- name: FindUserWebhooks :many
+SELECT * FROM webhooks
+WHERE user_id = ?;
+
+-- name: FindSubscriptionWebhooks :many
+SELECT * FROM webhooks
+WHERE user_id = ? AND (subscription_id = '' OR subscription_id = ?);
+
+-- name: DeleteUserWebhooks :exec
+DELETE FROM webhooks
+WHERE user_id = ? AND id IN (sqlc.slice('ids'));
+
+-- name: DeleteWebhooksInSubscriptions :exec
+DELETE FROM webhooks
+WHERE subscription_id IN (sqlc.slice('ids'));
@@ -2,17 +2,19 @@ package bot
 
 import (
 	"context"
+	"database/sql"
+	"errors"
 	"fmt"
 	"log/slog"
 	"runtime/debug"
-	"strconv"
-	"time"
 
 	"github.com/bwmarrin/discordgo"
 	"github.com/robherley/gw-bot/internal/bot/cmd"
 	"github.com/robherley/gw-bot/internal/db"
 	"github.com/robherley/gw-bot/internal/db/sqlgen"
 	"github.com/robherley/gw-bot/internal/gw"
+	"github.com/robherley/gw-bot/internal/notify"
+	"github.com/robherley/gw-bot/internal/tracker"
 )
 
 // MaxMessagesPerNotify is the maximum number of messages to send in a single notify.
@@ -21,13 +23,12 @@ const MaxMessagesPerNotify = 10
 
 type Bot struct {
 	db       db.DB
-	gw       *gw.Client
 	ctx      context.Context
 	session  *discordgo.Session
 	handlers map[string]cmd.Handler
 }
 
-func New(ctx context.Context, token string, db db.DB, gw *gw.Client) (*Bot, error) {
+func New(ctx context.Context, token string, db db.DB, gw *gw.Client, tracker *tracker.Tracker, webhook *notify.Webhook, email *notify.Email) (*Bot, error) {
 	session, err := discordgo.New("Bot " + token)
 	if err != nil {
 		return nil, err
@@ -37,18 +38,37 @@ func New(ctx context.Context, token string, db db.DB, gw *gw.Client) (*Bot, erro
 
 	b := &Bot{
 		db:      db,
-		gw:      gw,
 		ctx:     ctx,
 		session: session,
 	}
 
-	b.handlers = make(map[string]cmd.Handler)
-	for _, handler := range []cmd.Handler{
+	handlers := []cmd.Handler{
 		cmd.NewPing(),
-		cmd.NewSubscribe(db, gw),
+		cmd.NewSubscribe(db, gw, tracker),
 		cmd.NewUnsubscribe(db),
-		cmd.NewSubscriptions(db),
-	} {
+		cmd.NewSubscriptions(db, gw),
+		cmd.NewEdit(db, tracker),
+		cmd.NewPause(db),
+		cmd.NewResume(db, tracker),
+		cmd.NewVacation(db, tracker),
+		cmd.NewFilters(db, tracker),
+		cmd.NewWebhook(db, webhook),
+		cmd.NewDigest(db),
+		cmd.NewPriority(db),
+		cmd.NewSettings(db),
+		cmd.NewWatch(db, gw),
+		cmd.NewWatchlist(db),
+		cmd.NewComps(gw),
+		cmd.NewSearch(db, gw, tracker),
+	}
+
+	// email is optional, since it needs an SMTP server
+	if email != nil {
+		handlers = append(handlers, cmd.NewEmail(db, email))
+	}
+
+	b.handlers = make(map[string]cmd.Handler)
+	for _, handler := range handlers {
 		b.handlers[handler.Name()] = handler
 	}
 
@@ -73,8 +93,11 @@ func (b *Bot) Start() (err error) {
 			}
 		}()
 
+		// someone is waiting on commands, so their requests to ShopGoodwill go ahead of polling
+		ctx := gw.WithPriority(b.ctx, gw.PriorityInteractive)
+
 		switch i.Type {
-		case discordgo.InteractionApplicationCommand:
+		case discordgo.InteractionApplicationCommand, discordgo.InteractionApplicationCommandAutocomplete:
 			handler, ok := b.handlers[i.ApplicationCommandData().Name]
 			if !ok {
 				log.Warn("no handler found")
@@ -82,23 +105,36 @@ func (b *Bot) Start() (err error) {
 			}
 
 			log.Info("invoking command")
-			if err := handler.Handle(b.ctx, s, i); err != nil {
-				log.Error("failed", "err", err)
+			if err := handler.Handle(ctx, s, i); err != nil {
+				errorID := cmd.NewErrorID()
+				log.Error("failed", "err", err, "error_id", errorID)
+				// autocomplete can only respond with choices
+				if i.Type == discordgo.InteractionApplicationCommand {
+					b.respondError(log, s, i, errorID)
+				}
+			}
+		case discordgo.InteractionMessageComponent, discordgo.InteractionModalSubmit:
+			var customID string
+			if i.Type == discordgo.InteractionModalSubmit {
+				// already included by LogWith
+				customID = i.ModalSubmitData().CustomID
+			} else {
+				customID = i.MessageComponentData().CustomID
+				log = log.With("custom_id", customID)
 			}
-		case discordgo.InteractionMessageComponent:
-			customID := i.MessageComponentData().CustomID
-			log = log.With("custom_id", customID)
 
-			cmd, _ := cmd.FromCustomID(customID)
-			handler, ok := b.handlers[cmd]
+			name, _ := cmd.FromCustomID(customID)
+			handler, ok := b.handlers[name]
 			if !ok {
 				log.Warn("no handler found")
 				return
 			}
 
 			log.Info("invoking command")
-			if err := handler.Handle(b.ctx, s, i); err != nil {
-				log.Error("failed", "err", err)
+			if err := handler.Handle(ctx, s, i); err != nil {
+				errorID := cmd.NewErrorID()
+				log.Error("failed", "err", err, "error_id", errorID)
+				b.respondError(log, s, i, errorID)
 			}
 		default:
 			log.Warn("unknown interaction type")
@@ -108,6 +144,12 @@ func (b *Bot) Start() (err error) {
 	return nil
 }
 
+func (b *Bot) respondError(log *slog.Logger, s *discordgo.Session, i *discordgo.InteractionCreate, errorID string) {
+	if err := cmd.RespondError(s, i, errorID); err != nil {
+		log.Error("failed to respond with error", "err", err)
+	}
+}
+
 func (b *Bot) Close() error {
 	return b.session.Close()
 }
@@ -166,122 +208,92 @@ func (b *Bot) Register(guild string) error {
 	return nil
 }
 
-func (b *Bot) NotifyNewItems(sub sqlgen.Subscription, items []gw.Item) error {
-	dm, err := b.session.UserChannelCreate(sub.UserID)
+// Notify sends the notification as a DM to the subscription's user.
+func (b *Bot) Notify(ctx context.Context, n notify.Notification) error {
+	var color int
+	switch n.Kind {
+	case notify.KindNewItems:
+		color = 0x00CB74
+	case notify.KindEndingSoon:
+		color = 0xF24E43
+	case notify.KindDigest:
+		color = 0x5865F2
+	case notify.KindWatched:
+		color = 0xF5A623
+	case notify.KindItemUpdate:
+		color = 0xFEE75C
+	case notify.KindSold:
+		color = 0x99AAB5
+	default:
+		return fmt.Errorf("unknown notification kind: %q", n.Kind)
+	}
+
+	dm, err := b.session.UserChannelCreate(n.Subscription.UserID, discordgo.WithContext(ctx))
 	if err != nil {
 		return err
 	}
 
-	chunks := Chunk(items, MaxMessagesPerNotify)
-	for _, chunk := range chunks {
-		embeds := make([]*discordgo.MessageEmbed, 0, len(chunk))
-		for _, item := range chunk {
-			embed := b.ItemToEmbed(item)
-			embed.Color = 0x00CB74
-			embeds = append(embeds, embed)
-		}
+	content := n.Summary()
+	if n.Overflow > 0 {
+		content += fmt.Sprintf("\n…and %d more", n.Overflow)
+	}
 
-		_, err := b.session.ChannelMessageSendComplex(dm.ID, &discordgo.MessageSend{
-			Content: fmt.Sprintf("🔔 New items for %q!", sub.Term),
-			Embeds:  embeds,
+	// sold reports are replies to the first alert about the item, if there was one
+	var reference *discordgo.MessageReference
+	if n.Kind == notify.KindSold && len(n.Items) > 0 {
+		alert, err := b.db.FindAlertMessage(ctx, sqlgen.FindAlertMessageParams{
+			UserID:     n.Subscription.UserID,
+			GoodwillID: n.Items[0].ItemID,
 		})
-		if err != nil {
+		if err == nil {
+			failIfNotExists := false
+			reference = &discordgo.MessageReference{
+				MessageID:       alert.MessageID,
+				ChannelID:       alert.ChannelID,
+				FailIfNotExists: &failIfNotExists,
+			}
+		} else if !errors.Is(err, sql.ErrNoRows) {
 			return err
 		}
-
-		time.Sleep(2 * time.Second)
-	}
-
-	return nil
-}
-
-func (b *Bot) NotifyEndingSoonItems(sub sqlgen.Subscription, items []sqlgen.Item) error {
-	log := slog.With("subscription_id", sub.ID, "user_id", sub.UserID)
-
-	gwItems := make([]*gw.Item, 0, len(items))
-	for _, item := range items {
-		gwItem, err := b.gw.FindItem(b.ctx, item.GoodwillID)
-		if err != nil {
-			log.Error("failed to find item", "error", err, "goodwill_id", item.GoodwillID)
-			continue
-		}
-
-		gwItems = append(gwItems, gwItem)
-	}
-
-	dm, err := b.session.UserChannelCreate(sub.UserID)
-	if err != nil {
-		return err
 	}
 
-	chunks := Chunk(gwItems, MaxMessagesPerNotify)
+	chunks := Chunk(n.Items, MaxMessagesPerNotify)
 	for _, chunk := range chunks {
 		embeds := make([]*discordgo.MessageEmbed, 0, len(chunk))
 		for _, item := range chunk {
-			embed := b.ItemToEmbed(*item)
-			embed.Color = 0xF24E43
+			embed := cmd.ItemToEmbed(item)
+			embed.Color = color
+			embed.Footer = &discordgo.MessageEmbedFooter{
+				Text: "Ends " + n.EndTime(item),
+			}
 			embeds = append(embeds, embed)
 		}
 
-		_, err := b.session.ChannelMessageSendComplex(dm.ID, &discordgo.MessageSend{
-			Content: fmt.Sprintf("⏰ Items ending soon for %q!", sub.Term),
-			Embeds:  embeds,
-		})
+		msg, err := b.session.ChannelMessageSendComplex(dm.ID, &discordgo.MessageSend{
+			Content:   content,
+			Embeds:    embeds,
+			Reference: reference,
+		}, discordgo.WithContext(ctx))
 		if err != nil {
 			return err
 		}
 
-		time.Sleep(2 * time.Second)
-	}
-	return nil
-}
-
-func (b *Bot) ItemToEmbed(item gw.Item) *discordgo.MessageEmbed {
-	embed := &discordgo.MessageEmbed{
-		Title: item.Title,
-		Fields: []*discordgo.MessageEmbedField{
-			{
-				Name:   "Current Price",
-				Value:  fmt.Sprintf("$%.2f", item.CurrentPrice),
-				Inline: true,
-			},
-			{
-				Name:   "Ends",
-				Value:  item.RelativeEndTime(),
-				Inline: true,
-			},
-			{
-				Name:   "Bids",
-				Value:  strconv.FormatInt(item.NumBids, 10),
-				Inline: true,
-			},
-			{
-				Name:   "Category",
-				Value:  item.CategoryName,
-				Inline: true,
-			},
-			{
-				Name:   "Kind",
-				Value:  item.Kind(),
-				Inline: true,
-			},
-		},
-		URL: item.URL(),
-	}
-
-	if item.ImageURL != "" {
-		embed.Image = &discordgo.MessageEmbedImage{
-			URL: item.ImageURL,
+		// alerts are kept so their items are checked again for updates, and sold reports can reply to them
+		if n.Kind != notify.KindSold {
+			for _, item := range chunk {
+				if err := b.db.CreateAlertMessage(ctx, sqlgen.CreateAlertMessageParams{
+					UserID:     n.Subscription.UserID,
+					GoodwillID: item.ItemID,
+					ChannelID:  msg.ChannelID,
+					MessageID:  msg.ID,
+				}); err != nil {
+					slog.Error("failed to create alert message", "error", err, "user_id", n.Subscription.UserID, "goodwill_id", item.ItemID)
+				}
+			}
 		}
 	}
 
-	slog.Info("embedding item",
-		"item_id", item.ItemID,
-		"url", item.URL(),
-		"image_url", item.ImageURL,
-	)
-
-	return embed
+	return nil
 }
 
 func Chunk[T any](slice []T, chunkSize int) [][]T {
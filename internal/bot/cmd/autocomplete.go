@@ -0,0 +1,97 @@
+package cmd
+
+import (
+	"context"
+	"log/slog"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+	"github.com/robherley/gw-bot/internal/db/sqlgen"
+	"github.com/robherley/gw-bot/internal/gw"
+)
+
+// MaxAutocompleteChoices is the discord maximum of choices for an autocomplete response.
+const MaxAutocompleteChoices = 25
+
+// AutocompleteTimeout is how long choices are looked up for, discord drops autocomplete responses after 3 seconds.
+const AutocompleteTimeout = 2 * time.Second
+
+func FocusedOption(i *discordgo.InteractionCreate) *discordgo.ApplicationCommandInteractionDataOption {
+	return focusedOption(i.ApplicationCommandData().Options)
+}
+
+func focusedOption(options []*discordgo.ApplicationCommandInteractionDataOption) *discordgo.ApplicationCommandInteractionDataOption {
+	for _, option := range options {
+		if option.Focused {
+			return option
+		}
+
+		// options of subcommands are nested
+		if focused := focusedOption(option.Options); focused != nil {
+			return focused
+		}
+	}
+	return nil
+}
+
+func RespondChoices(s *discordgo.Session, i *discordgo.InteractionCreate, choices []*discordgo.ApplicationCommandOptionChoice) error {
+	return s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+		Type: discordgo.InteractionApplicationCommandAutocompleteResult,
+		Data: &discordgo.InteractionResponseData{
+			Choices: choices,
+		},
+	})
+}
+
+// CategoryChoices returns the categories matching query. There are no choices if the categories couldn't be fetched
+// in time, they're usually cached when the bot starts.
+func CategoryChoices(ctx context.Context, client *gw.Client, query string) []*discordgo.ApplicationCommandOptionChoice {
+	ctx, cancel := context.WithTimeout(ctx, AutocompleteTimeout)
+	defer cancel()
+
+	tree, err := client.Categories(ctx)
+	if err != nil {
+		slog.Warn("failed to find categories for autocomplete", "error", err)
+		return nil
+	}
+
+	categories := tree.Search(query, MaxAutocompleteChoices)
+	choices := make([]*discordgo.ApplicationCommandOptionChoice, 0, len(categories))
+	for _, category := range categories {
+		name := category.FullName
+		if runes := []rune(name); len(runes) > 100 {
+			name = "…" + string(runes[len(runes)-99:])
+		}
+
+		choices = append(choices, &discordgo.ApplicationCommandOptionChoice{
+			Name:  name,
+			Value: strconv.FormatInt(category.ID, 10),
+		})
+	}
+
+	return choices
+}
+
+func SubscriptionChoices(subs []sqlgen.Subscription, query string) []*discordgo.ApplicationCommandOptionChoice {
+	query = strings.ToLower(strings.TrimSpace(query))
+
+	choices := make([]*discordgo.ApplicationCommandOptionChoice, 0, len(subs))
+	for _, sub := range subs {
+		if len(choices) == MaxAutocompleteChoices {
+			break
+		}
+
+		if !strings.Contains(strings.ToLower(sub.Term), query) {
+			continue
+		}
+
+		choices = append(choices, &discordgo.ApplicationCommandOptionChoice{
+			Name:  sub.Term,
+			Value: sub.ID,
+		})
+	}
+
+	return choices
+}
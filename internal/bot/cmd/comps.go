@@ -0,0 +1,160 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/bwmarrin/discordgo"
+	"github.com/robherley/gw-bot/internal/gw"
+	"github.com/robherley/gw-bot/internal/query"
+)
+
+const (
+	DefaultCompsDays = 7
+	MaxCompsDays     = 30
+	// MaxCompsExamples is how many of the most recently sold items are shown with the prices.
+	MaxCompsExamples = 5
+	// MaxCompsPages is how many pages of closed auctions are searched, popular terms only get a sample of them.
+	MaxCompsPages = 5
+)
+
+func NewComps(gw *gw.Client) Handler {
+	return &Comps{gw}
+}
+
+type Comps struct {
+	gw *gw.Client
+}
+
+func (cmd *Comps) Name() string {
+	return "comps"
+}
+
+func (cmd *Comps) Description() string {
+	return "See what items sold for in closed auctions."
+}
+
+func (cmd *Comps) Options() []*discordgo.ApplicationCommandOption {
+	termMinLength := 1
+	termMaxLength := 100
+	daysMinValue := float64(1)
+	return []*discordgo.ApplicationCommandOption{
+		{
+			Type:        discordgo.ApplicationCommandOptionString,
+			Name:        "term",
+			Description: "What items to look up, supports the same syntax as /subscribe",
+			MinLength:   &termMinLength,
+			MaxLength:   termMaxLength,
+			Required:    true,
+		},
+		{
+			Type:        discordgo.ApplicationCommandOptionInteger,
+			Name:        "days",
+			Description: fmt.Sprintf("How many days back to look, defaults to %d", DefaultCompsDays),
+			Required:    false,
+			MinValue:    &daysMinValue,
+			MaxValue:    MaxCompsDays,
+		},
+	}
+}
+
+func (cmd *Comps) Handle(ctx context.Context, s *discordgo.Session, i *discordgo.InteractionCreate) error {
+	if i.Type != discordgo.InteractionApplicationCommand {
+		return nil
+	}
+
+	if err := DeferResponse(s, i); err != nil {
+		return err
+	}
+
+	var (
+		term string
+		days = DefaultCompsDays
+	)
+
+	for _, option := range i.ApplicationCommandData().Options {
+		switch option.Name {
+		case "term":
+			term = option.StringValue()
+		case "days":
+			days = int(option.IntValue())
+		}
+	}
+
+	q, err := query.Parse(term)
+	if err != nil {
+		return EditResponse(s, i, fmt.Sprintf("⛔ Invalid term %q: %s", term, err))
+	}
+
+	opts := []gw.SearchOption{gw.WithClosedAuctions(days)}
+	if q.MinPrice != nil {
+		opts = append(opts, gw.WithMinPrice(*q.MinPrice))
+	}
+	if q.MaxPrice != nil {
+		opts = append(opts, gw.WithMaxPrice(*q.MaxPrice))
+	}
+
+	// the pages are searched here instead of with Pages, which doesn't say how many items there are in total
+	matched := make([]gw.Item, 0)
+	searched, total := 0, 0
+	for page := 1; page <= MaxCompsPages; page++ {
+		res, err := cmd.gw.SearchPage(ctx, q.Text, append(slices.Clip(opts), gw.WithPage(page))...)
+		if err != nil {
+			return err
+		}
+
+		total = res.Total
+		searched += len(res.Items)
+		for _, item := range res.Items {
+			if q.Match(item) {
+				matched = append(matched, item)
+			}
+		}
+
+		if len(res.Items) == 0 || searched >= res.Total {
+			break
+		}
+	}
+
+	// popular terms have more closed auctions than MaxCompsPages, so only a sample of them were searched
+	sample := searched > 0 && searched < total
+
+	return EditResponse(s, i, FormatComps(term, days, matched, sample, searched))
+}
+
+// FormatComps summarizes the prices of items. If sample is true, they're only the first searched items of the
+// closed auctions, which is mentioned with the stats.
+func FormatComps(term string, days int, items []gw.Item, sample bool, searched int) string {
+	stats := gw.NewPriceStats(items)
+	if stats.Count == 0 {
+		if sample {
+			return fmt.Sprintf("🔍 Nothing sold for %q in the first %d closed auctions searched from the last %d day(s), there may be more.", term, searched, days)
+		}
+		return fmt.Sprintf("🔍 Nothing sold for %q in the last %d day(s).", term, days)
+	}
+
+	builder := strings.Builder{}
+	if sample {
+		builder.WriteString(fmt.Sprintf("🔍 %d item(s) sold for %q in a sample of the first %d closed auctions searched from the last %d day(s), there may be more:\n", stats.Count, term, searched, days))
+	} else {
+		builder.WriteString(fmt.Sprintf("🔍 %d item(s) sold for %q in the last %d day(s):\n", stats.Count, term, days))
+	}
+	builder.WriteString(fmt.Sprintf("- Min: $%.2f\n", stats.Min))
+	builder.WriteString(fmt.Sprintf("- Median: $%.2f\n", stats.Median))
+	builder.WriteString(fmt.Sprintf("- 90th percentile: $%.2f\n", stats.P90))
+	builder.WriteString(fmt.Sprintf("- Max: $%.2f\n", stats.Max))
+
+	sold := gw.Sold(items)
+	if len(sold) > MaxCompsExamples {
+		sold = sold[:MaxCompsExamples]
+	}
+
+	builder.WriteString("Recently sold:\n")
+	for _, item := range sold {
+		builder.WriteString(fmt.Sprintf("- [%s](<%s>) $%.2f with %d bid(s), ended <t:%d:R>\n", item.Title, item.URL(), item.CurrentPrice, item.NumBids, item.EndTime.Unix()))
+	}
+
+	return builder.String()
+}
@@ -0,0 +1,195 @@
+package cmd
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log/slog"
+
+	"github.com/bwmarrin/discordgo"
+	"github.com/robherley/gw-bot/internal/db"
+	"github.com/robherley/gw-bot/internal/db/sqlgen"
+	"github.com/robherley/gw-bot/internal/notify"
+)
+
+// DeliveryDefault makes a subscription follow the user's delivery mode.
+const DeliveryDefault = "default"
+
+func NewDigest(db db.DB) Handler {
+	return &Digest{db}
+}
+
+type Digest struct {
+	db db.DB
+}
+
+func (cmd *Digest) Name() string {
+	return "digest"
+}
+
+func (cmd *Digest) Description() string {
+	return "Get new items in an hourly or daily digest instead of right away."
+}
+
+func (cmd *Digest) Options() []*discordgo.ApplicationCommandOption {
+	hourMinValue := float64(0)
+	return []*discordgo.ApplicationCommandOption{
+		{
+			Type:        discordgo.ApplicationCommandOptionString,
+			Name:        "mode",
+			Description: "How new items are sent",
+			Required:    true,
+			Choices: []*discordgo.ApplicationCommandOptionChoice{
+				{Name: "Instant", Value: string(notify.DeliveryInstant)},
+				{Name: "Hourly digest", Value: string(notify.DeliveryHourly)},
+				{Name: "Daily digest", Value: string(notify.DeliveryDaily)},
+				{Name: "Same as my other subscriptions", Value: DeliveryDefault},
+			},
+		},
+		{
+			Type:         discordgo.ApplicationCommandOptionString,
+			Name:         "subscription",
+			Description:  "Only change this subscription. Changes the default for all subscriptions if not set",
+			Required:     false,
+			Autocomplete: true,
+		},
+		{
+			Type:        discordgo.ApplicationCommandOptionInteger,
+			Name:        "hour",
+			Description: "Hour of the day to send daily digests at in your /settings timezone, defaults to 9",
+			Required:    false,
+			MinValue:    &hourMinValue,
+			MaxValue:    23,
+		},
+	}
+}
+
+func (cmd *Digest) Handle(ctx context.Context, s *discordgo.Session, i *discordgo.InteractionCreate) error {
+	userID := UserID(i)
+	if userID == "" {
+		return nil
+	}
+
+	switch i.Type {
+	case discordgo.InteractionApplicationCommand:
+		if err := DeferResponse(s, i); err != nil {
+			return err
+		}
+
+		var (
+			mode  string
+			subID string
+			hour  *int64
+		)
+
+		for _, option := range i.ApplicationCommandData().Options {
+			switch option.Name {
+			case "mode":
+				mode = option.StringValue()
+			case "subscription":
+				subID = option.StringValue()
+			case "hour":
+				h := option.IntValue()
+				hour = &h
+			}
+		}
+
+		settings, err := cmd.db.FindUserSettings(ctx, userID)
+		if errors.Is(err, sql.ErrNoRows) {
+			settings = sqlgen.UserSetting{
+				Delivery:   string(notify.DeliveryInstant),
+				DigestHour: notify.DefaultDigestHour,
+			}
+		} else if err != nil {
+			return err
+		}
+
+		if hour != nil {
+			settings.DigestHour = *hour
+		}
+
+		if subID == "" {
+			if mode == DeliveryDefault {
+				return EditResponse(s, i, "⛔ Pick a subscription to make it follow your default mode.")
+			}
+			settings.Delivery = mode
+		}
+
+		if err := cmd.db.SetUserDelivery(ctx, sqlgen.SetUserDeliveryParams{
+			UserID:     userID,
+			Delivery:   settings.Delivery,
+			DigestHour: settings.DigestHour,
+		}); err != nil {
+			return err
+		}
+
+		log := slog.With("user_id", userID)
+
+		if subID == "" {
+			log.Info("set delivery", "delivery", settings.Delivery, "digest_hour", settings.DigestHour)
+
+			schedule := notify.ScheduleFor(sqlgen.Subscription{}, &settings)
+			return EditResponse(s, i, fmt.Sprintf("✅ New items will be sent %s, unless a subscription has its own mode.", FormatSchedule(schedule)))
+		}
+
+		if mode == DeliveryDefault {
+			mode = ""
+		}
+
+		subs, err := cmd.db.FindUserSubscriptions(ctx, userID)
+		if err != nil {
+			return err
+		}
+
+		var sub *sqlgen.Subscription
+		for _, candidate := range subs {
+			if candidate.ID == subID {
+				sub = &candidate
+			}
+		}
+
+		if sub == nil {
+			return EditResponse(s, i, "⛔ Unknown subscription, please pick one from the list.")
+		}
+
+		if err := cmd.db.SetSubscriptionDelivery(ctx, sqlgen.SetSubscriptionDeliveryParams{
+			Delivery: mode,
+			ID:       sub.ID,
+			UserID:   userID,
+		}); err != nil {
+			return err
+		}
+
+		log.Info("set subscription delivery", "subscription_id", sub.ID, "delivery", mode)
+
+		sub.Delivery = mode
+		schedule := notify.ScheduleFor(*sub, &settings)
+		return EditResponse(s, i, fmt.Sprintf("✅ New items for %q will be sent %s.", sub.Term, FormatSchedule(schedule)))
+	case discordgo.InteractionApplicationCommandAutocomplete:
+		option := FocusedOption(i)
+		if option == nil || option.Name != "subscription" {
+			return RespondChoices(s, i, nil)
+		}
+
+		subs, err := cmd.db.FindUserSubscriptions(ctx, userID)
+		if err != nil {
+			return err
+		}
+
+		return RespondChoices(s, i, SubscriptionChoices(subs, option.StringValue()))
+	default:
+		return nil
+	}
+}
+
+func FormatSchedule(schedule notify.DigestSchedule) string {
+	switch schedule.Delivery {
+	case notify.DeliveryHourly:
+		return "in an hourly digest"
+	case notify.DeliveryDaily:
+		return fmt.Sprintf("in a daily digest at %02d:00 %s", schedule.Hour, schedule.Location)
+	default:
+		return "right away"
+	}
+}
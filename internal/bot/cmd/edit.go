@@ -0,0 +1,250 @@
+package cmd
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strconv"
+
+	"github.com/bwmarrin/discordgo"
+	"github.com/mattn/go-sqlite3"
+	"github.com/robherley/gw-bot/internal/db"
+	"github.com/robherley/gw-bot/internal/db/sqlgen"
+	"github.com/robherley/gw-bot/internal/query"
+	"github.com/robherley/gw-bot/internal/tracker"
+)
+
+func NewEdit(db db.DB, tracker *tracker.Tracker) Handler {
+	return &Edit{db, tracker}
+}
+
+type Edit struct {
+	db      db.DB
+	tracker *tracker.Tracker
+}
+
+func (cmd *Edit) Name() string {
+	return "edit"
+}
+
+func (cmd *Edit) Description() string {
+	return "Edit an existing subscription."
+}
+
+func (cmd *Edit) Handle(ctx context.Context, s *discordgo.Session, i *discordgo.InteractionCreate) error {
+	userID := UserID(i)
+	if userID == "" {
+		return nil
+	}
+
+	switch i.Type {
+	case discordgo.InteractionApplicationCommand:
+		if err := DeferResponse(s, i); err != nil {
+			return err
+		}
+
+		subs, err := cmd.db.FindUserSubscriptions(ctx, userID)
+		if err != nil {
+			return err
+		}
+
+		if len(subs) == 0 {
+			return EditResponse(s, i, "ℹ️ You have no subscriptions to edit.")
+		}
+
+		return EditResponseComplex(s, i, &discordgo.WebhookEdit{
+			Components: &[]discordgo.MessageComponent{
+				discordgo.ActionsRow{
+					Components: []discordgo.MessageComponent{
+						discordgo.SelectMenu{
+							CustomID:    cmd.Name() + ":select",
+							Placeholder: "✏️ What subscription would you like to edit?",
+							Options:     SubscriptionSelectOptions(subs),
+						},
+					},
+				},
+			},
+		})
+	case discordgo.InteractionMessageComponent:
+		values := i.MessageComponentData().Values
+		if len(values) == 0 {
+			return nil
+		}
+
+		sub, err := cmd.db.FindSubscription(ctx, values[0])
+		if err != nil {
+			return err
+		}
+
+		if sub.UserID != userID {
+			return nil
+		}
+
+		return s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+			Type: discordgo.InteractionResponseModal,
+			Data: &discordgo.InteractionResponseData{
+				CustomID:   cmd.Name() + ":" + sub.ID,
+				Title:      "Edit subscription",
+				Components: cmd.modalComponents(sub),
+			},
+		})
+	case discordgo.InteractionModalSubmit:
+		if err := DeferResponse(s, i); err != nil {
+			return err
+		}
+
+		_, args := FromCustomID(i.ModalSubmitData().CustomID)
+		if len(args) == 0 {
+			return nil
+		}
+
+		sub, err := cmd.db.FindSubscription(ctx, args[0])
+		if errors.Is(err, sql.ErrNoRows) {
+			return EditResponse(s, i, "⛔ That subscription no longer exists.")
+		} else if err != nil {
+			return err
+		}
+
+		if sub.UserID != userID {
+			return nil
+		}
+
+		values := ModalValues(i.ModalSubmitData())
+
+		params := sqlgen.UpdateSubscriptionParams{
+			ID:     sub.ID,
+			UserID: userID,
+			Term:   values["term"],
+		}
+
+		q, err := query.Parse(params.Term)
+		if err != nil {
+			return EditResponse(s, i, fmt.Sprintf("⛔ Invalid term %q: %s", params.Term, err))
+		}
+
+		if params.MinPrice, err = parseOptionalInt(values["min"]); err != nil {
+			return EditResponse(s, i, "⛔ Minimum price must be a whole number.")
+		}
+
+		if params.MaxPrice, err = parseOptionalInt(values["max"]); err != nil {
+			return EditResponse(s, i, "⛔ Maximum price must be a whole number.")
+		}
+
+		if params.Reminders, err = ParseReminders(values["reminders"]); err != nil {
+			return EditResponse(s, i, fmt.Sprintf("⛔ %s.", err))
+		}
+
+		if params.MinPrice != nil && params.MaxPrice != nil && *params.MinPrice > *params.MaxPrice {
+			return EditResponse(s, i, "⛔ Minimum price must be less than or equal to maximum price.")
+		}
+
+		updated, err := cmd.db.UpdateSubscription(ctx, params)
+		if err != nil {
+			var sqliteErr sqlite3.Error
+			if errors.As(err, &sqliteErr) && errors.Is(sqliteErr.ExtendedCode, sqlite3.ErrConstraintUnique) {
+				return EditResponse(s, i, fmt.Sprintf("⛔ Already subscribed for search: %q.", params.Term))
+			}
+			return err
+		}
+
+		log := slog.With("subscription_id", updated.ID, "user_id", updated.UserID)
+		log.Info("updated subscription")
+
+		// a different search would otherwise notify on everything that is already listed
+		if searchChanged(sub, updated) {
+			n, err := cmd.tracker.Seed(ctx, updated)
+			if err != nil {
+				log.Error("failed to seed items", "error", err)
+			}
+
+			log.Info("seeded items", "count", n)
+		}
+
+		msg := fmt.Sprintf("✅ Updated subscription for term: %q", updated.Term)
+		if !q.IsPlain() {
+			msg += "\n" + DescribeQuery(q)
+		}
+
+		return EditResponse(s, i, msg)
+	default:
+		return nil
+	}
+}
+
+func (cmd *Edit) modalComponents(sub sqlgen.Subscription) []discordgo.MessageComponent {
+	formatOptionalInt := func(v *int64) string {
+		if v == nil {
+			return ""
+		}
+		return strconv.FormatInt(*v, 10)
+	}
+
+	inputs := []discordgo.TextInput{
+		{
+			CustomID:  "term",
+			Label:     "Term",
+			Style:     discordgo.TextInputShort,
+			Value:     sub.Term,
+			Required:  true,
+			MinLength: 1,
+			MaxLength: 100,
+		},
+		{
+			CustomID:    "min",
+			Label:       "Minimum price",
+			Style:       discordgo.TextInputShort,
+			Value:       formatOptionalInt(sub.MinPrice),
+			Placeholder: "No minimum",
+		},
+		{
+			CustomID:    "max",
+			Label:       "Maximum price",
+			Style:       discordgo.TextInputShort,
+			Value:       formatOptionalInt(sub.MaxPrice),
+			Placeholder: "No maximum",
+		},
+		{
+			CustomID:    "reminders",
+			Label:       "Reminders before the auction ends",
+			Style:       discordgo.TextInputShort,
+			Value:       FormatReminders(sub.Reminders),
+			Placeholder: "24h, 1h, 5m",
+			Required:    true,
+		},
+	}
+
+	components := make([]discordgo.MessageComponent, 0, len(inputs))
+	for _, input := range inputs {
+		components = append(components, discordgo.ActionsRow{
+			Components: []discordgo.MessageComponent{input},
+		})
+	}
+
+	return components
+}
+
+func searchChanged(before, after sqlgen.Subscription) bool {
+	equal := func(a, b *int64) bool {
+		if a == nil || b == nil {
+			return a == b
+		}
+		return *a == *b
+	}
+
+	return before.Term != after.Term || !equal(before.MinPrice, after.MinPrice) || !equal(before.MaxPrice, after.MaxPrice)
+}
+
+func parseOptionalInt(value string) (*int64, error) {
+	if value == "" {
+		return nil, nil
+	}
+
+	v, err := strconv.ParseInt(value, 10, 64)
+	if err != nil {
+		return nil, err
+	}
+
+	return &v, nil
+}
@@ -0,0 +1,180 @@
+package cmd
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/mail"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+	"github.com/robherley/gw-bot/internal/db"
+	"github.com/robherley/gw-bot/internal/db/sqlgen"
+	"github.com/robherley/gw-bot/internal/notify"
+)
+
+func NewEmail(db db.DB, notifier *notify.Email) Handler {
+	return &Email{db, notifier}
+}
+
+type Email struct {
+	db       db.DB
+	notifier *notify.Email
+}
+
+func (cmd *Email) Name() string {
+	return "email"
+}
+
+func (cmd *Email) Description() string {
+	return "Also receive alerts by email."
+}
+
+func (cmd *Email) Options() []*discordgo.ApplicationCommandOption {
+	return []*discordgo.ApplicationCommandOption{
+		{
+			Type:        discordgo.ApplicationCommandOptionSubCommand,
+			Name:        "set",
+			Description: "Send a verification code to an email address",
+			Options: []*discordgo.ApplicationCommandOption{
+				{
+					Type:        discordgo.ApplicationCommandOptionString,
+					Name:        "address",
+					Description: "Where to send alerts to",
+					Required:    true,
+				},
+			},
+		},
+		{
+			Type:        discordgo.ApplicationCommandOptionSubCommand,
+			Name:        "verify",
+			Description: "Start receiving alerts with the code that was emailed to you",
+			Options: []*discordgo.ApplicationCommandOption{
+				{
+					Type:        discordgo.ApplicationCommandOptionString,
+					Name:        "code",
+					Description: "The six digit code from the email",
+					Required:    true,
+				},
+			},
+		},
+		{
+			Type:        discordgo.ApplicationCommandOptionSubCommand,
+			Name:        "remove",
+			Description: "Stop receiving alerts by email",
+		},
+	}
+}
+
+func (cmd *Email) Handle(ctx context.Context, s *discordgo.Session, i *discordgo.InteractionCreate) error {
+	if i.Type != discordgo.InteractionApplicationCommand {
+		return nil
+	}
+
+	userID := UserID(i)
+	if userID == "" {
+		return nil
+	}
+
+	// email addresses shouldn't be shared with the channel
+	if err := DeferEphemeralResponse(s, i); err != nil {
+		return err
+	}
+
+	options := i.ApplicationCommandData().Options
+	if len(options) == 0 {
+		return nil
+	}
+
+	log := slog.With("user_id", userID)
+
+	switch options[0].Name {
+	case "set":
+		var address string
+		for _, option := range options[0].Options {
+			if option.Name == "address" {
+				address = option.StringValue()
+			}
+		}
+
+		addr, err := mail.ParseAddress(address)
+		if err != nil {
+			return EditResponse(s, i, fmt.Sprintf("⛔ Invalid email address %q.", address))
+		}
+
+		settings, err := cmd.db.FindUserSettings(ctx, userID)
+		if err != nil && !errors.Is(err, sql.ErrNoRows) {
+			return err
+		}
+
+		// the expiry is kept after removing an address, so codes can't be sent to anyone over and over
+		if settings.EmailCodeExpiresAt != nil && time.Now().Before(*settings.EmailCodeExpiresAt) {
+			return EditResponse(s, i, fmt.Sprintf("⛔ A code was already sent, use `/email verify` with it or wait until <t:%d:t> to get a new one.", settings.EmailCodeExpiresAt.Unix()))
+		}
+
+		code, err := notify.NewEmailCode()
+		if err != nil {
+			return err
+		}
+
+		expiresAt := time.Now().Add(notify.EmailCodeTTL).UTC()
+		if err := cmd.db.StartUserEmailVerification(ctx, sqlgen.StartUserEmailVerificationParams{
+			UserID:             userID,
+			PendingEmail:       &addr.Address,
+			EmailCode:          &code,
+			EmailCodeExpiresAt: &expiresAt,
+		}); err != nil {
+			return err
+		}
+
+		if err := cmd.notifier.SendCode(ctx, addr.Address, code); err != nil {
+			return err
+		}
+
+		log.Info("sent email verification code")
+
+		return EditResponse(s, i, fmt.Sprintf("📧 Sent a code to %s, use `/email verify` with it before <t:%d:t>.", addr.Address, expiresAt.Unix()))
+	case "verify":
+		var code string
+		for _, option := range options[0].Options {
+			if option.Name == "code" {
+				code = option.StringValue()
+			}
+		}
+
+		// the attempt is counted before checking the code, so guesses can't race past the limit
+		settings, err := cmd.db.AddUserEmailCodeAttempt(ctx, userID)
+		if err != nil && !errors.Is(err, sql.ErrNoRows) {
+			return err
+		}
+
+		if settings.EmailCodeAttempts > notify.MaxEmailCodeAttempts && settings.EmailCodeExpiresAt != nil {
+			log.Warn("too many email verification attempts", "attempts", settings.EmailCodeAttempts)
+			return EditResponse(s, i, fmt.Sprintf("⛔ Too many wrong codes, use `/email set` to get a new one after <t:%d:t>.", settings.EmailCodeExpiresAt.Unix()))
+		}
+
+		if !notify.VerifyEmailCode(settings, code) {
+			return EditResponse(s, i, "⛔ That code is invalid or expired, use `/email set` to get a new one.")
+		}
+
+		if err := cmd.db.ConfirmUserEmail(ctx, userID); err != nil {
+			return err
+		}
+
+		log.Info("verified email")
+
+		return EditResponse(s, i, fmt.Sprintf("✅ Alerts will also be sent to %s!", *settings.PendingEmail))
+	case "remove":
+		if err := cmd.db.RemoveUserEmail(ctx, userID); err != nil {
+			return err
+		}
+
+		log.Info("removed email")
+
+		return EditResponse(s, i, "🔕 Alerts will no longer be sent by email.")
+	default:
+		return nil
+	}
+}
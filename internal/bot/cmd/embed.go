@@ -0,0 +1,58 @@
+package cmd
+
+import (
+	"fmt"
+	"log/slog"
+	"strconv"
+
+	"github.com/bwmarrin/discordgo"
+	"github.com/robherley/gw-bot/internal/gw"
+)
+
+func ItemToEmbed(item gw.Item) *discordgo.MessageEmbed {
+	embed := &discordgo.MessageEmbed{
+		Title: item.Title,
+		Fields: []*discordgo.MessageEmbedField{
+			{
+				Name:   "Current Price",
+				Value:  fmt.Sprintf("$%.2f", item.CurrentPrice),
+				Inline: true,
+			},
+			{
+				Name:   "Ends",
+				Value:  item.RelativeEndTime(),
+				Inline: true,
+			},
+			{
+				Name:   "Bids",
+				Value:  strconv.FormatInt(item.NumBids, 10),
+				Inline: true,
+			},
+			{
+				Name:   "Category",
+				Value:  item.CategoryName,
+				Inline: true,
+			},
+			{
+				Name:   "Kind",
+				Value:  item.Kind(),
+				Inline: true,
+			},
+		},
+		URL: item.URL(),
+	}
+
+	if item.ImageURL != "" {
+		embed.Image = &discordgo.MessageEmbedImage{
+			URL: item.ImageURL,
+		}
+	}
+
+	slog.Info("embedding item",
+		"item_id", item.ItemID,
+		"url", item.URL(),
+		"image_url", item.ImageURL,
+	)
+
+	return embed
+}
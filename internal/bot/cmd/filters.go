@@ -0,0 +1,223 @@
+package cmd
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strconv"
+	"strings"
+
+	"github.com/bwmarrin/discordgo"
+	"github.com/robherley/gw-bot/internal/db"
+	"github.com/robherley/gw-bot/internal/db/sqlgen"
+	"github.com/robherley/gw-bot/internal/gw"
+	"github.com/robherley/gw-bot/internal/tracker"
+)
+
+func NewFilters(db db.DB, tracker *tracker.Tracker) Handler {
+	return &Filters{db, tracker}
+}
+
+type Filters struct {
+	db      db.DB
+	tracker *tracker.Tracker
+}
+
+func (cmd *Filters) Name() string {
+	return "filters"
+}
+
+func (cmd *Filters) Description() string {
+	return "Edit the words a subscription must have or ignore."
+}
+
+func (cmd *Filters) Options() []*discordgo.ApplicationCommandOption {
+	return []*discordgo.ApplicationCommandOption{
+		{
+			Type:        discordgo.ApplicationCommandOptionBoolean,
+			Name:        "match_category",
+			Description: "Also match the words against the item category",
+			Required:    false,
+		},
+	}
+}
+
+func (cmd *Filters) Handle(ctx context.Context, s *discordgo.Session, i *discordgo.InteractionCreate) error {
+	userID := UserID(i)
+	if userID == "" {
+		return nil
+	}
+
+	switch i.Type {
+	case discordgo.InteractionApplicationCommand:
+		if err := DeferResponse(s, i); err != nil {
+			return err
+		}
+
+		// the category choice is carried through the custom IDs, since modals only have text inputs
+		var matchCategory string
+		for _, option := range i.ApplicationCommandData().Options {
+			if option.Name == "match_category" {
+				matchCategory = strconv.FormatBool(option.BoolValue())
+			}
+		}
+
+		subs, err := cmd.db.FindUserSubscriptions(ctx, userID)
+		if err != nil {
+			return err
+		}
+
+		if len(subs) == 0 {
+			return EditResponse(s, i, "ℹ️ You have no subscriptions to filter.")
+		}
+
+		return EditResponseComplex(s, i, &discordgo.WebhookEdit{
+			Components: &[]discordgo.MessageComponent{
+				discordgo.ActionsRow{
+					Components: []discordgo.MessageComponent{
+						discordgo.SelectMenu{
+							CustomID:    cmd.Name() + ":select:" + matchCategory,
+							Placeholder: "🧹 What subscription would you like to filter?",
+							Options:     SubscriptionSelectOptions(subs),
+						},
+					},
+				},
+			},
+		})
+	case discordgo.InteractionMessageComponent:
+		_, args := FromCustomID(i.MessageComponentData().CustomID)
+		values := i.MessageComponentData().Values
+		if len(values) == 0 {
+			return nil
+		}
+
+		sub, err := cmd.db.FindSubscription(ctx, values[0])
+		if err != nil {
+			return err
+		}
+
+		if sub.UserID != userID {
+			return nil
+		}
+
+		matchCategory := ""
+		if len(args) > 1 {
+			matchCategory = args[1]
+		}
+
+		return s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+			Type: discordgo.InteractionResponseModal,
+			Data: &discordgo.InteractionResponseData{
+				CustomID: cmd.Name() + ":" + sub.ID + ":" + matchCategory,
+				Title:    "Filters",
+				Components: []discordgo.MessageComponent{
+					discordgo.ActionsRow{
+						Components: []discordgo.MessageComponent{
+							discordgo.TextInput{
+								CustomID:    "exclude",
+								Label:       "Ignore items with any of these words",
+								Style:       discordgo.TextInputParagraph,
+								Value:       sub.ExcludeWords,
+								Placeholder: "cable, lens cap",
+								MaxLength:   1000,
+							},
+						},
+					},
+					discordgo.ActionsRow{
+						Components: []discordgo.MessageComponent{
+							discordgo.TextInput{
+								CustomID:    "require",
+								Label:       "Only alert on items with all of these words",
+								Style:       discordgo.TextInputParagraph,
+								Value:       sub.RequireWords,
+								Placeholder: "nikon",
+								MaxLength:   1000,
+							},
+						},
+					},
+				},
+			},
+		})
+	case discordgo.InteractionModalSubmit:
+		if err := DeferResponse(s, i); err != nil {
+			return err
+		}
+
+		_, args := FromCustomID(i.ModalSubmitData().CustomID)
+		if len(args) == 0 {
+			return nil
+		}
+
+		sub, err := cmd.db.FindSubscription(ctx, args[0])
+		if errors.Is(err, sql.ErrNoRows) {
+			return EditResponse(s, i, "⛔ That subscription no longer exists.")
+		} else if err != nil {
+			return err
+		}
+
+		if sub.UserID != userID {
+			return nil
+		}
+
+		values := ModalValues(i.ModalSubmitData())
+		filter := gw.Filter{
+			Exclude:       gw.ParseWords(values["exclude"]),
+			Require:       gw.ParseWords(values["require"]),
+			MatchCategory: sub.FilterCategory,
+		}
+
+		if len(args) > 1 && args[1] != "" {
+			filter.MatchCategory, _ = strconv.ParseBool(args[1])
+		}
+
+		updated, err := cmd.db.UpdateSubscriptionFilters(ctx, sqlgen.UpdateSubscriptionFiltersParams{
+			ExcludeWords:   gw.JoinWords(filter.Exclude),
+			RequireWords:   gw.JoinWords(filter.Require),
+			FilterCategory: filter.MatchCategory,
+			ID:             sub.ID,
+			UserID:         userID,
+		})
+		if err != nil {
+			return err
+		}
+
+		log := slog.With("subscription_id", updated.ID, "user_id", updated.UserID)
+		log.Info("updated subscription filters")
+
+		// items that were filtered out before are not tracked, so they'd all be notified as new
+		n, err := cmd.tracker.Seed(ctx, updated)
+		if err != nil {
+			log.Error("failed to seed items", "error", err)
+		}
+
+		log.Info("seeded items", "count", n)
+
+		msg := fmt.Sprintf("✅ Updated filters for term: %q", updated.Term)
+		if !filter.IsZero() {
+			msg += "\n" + FormatFilter(filter)
+		}
+
+		return EditResponse(s, i, msg)
+	default:
+		return nil
+	}
+}
+
+func FormatFilter(filter gw.Filter) string {
+	parts := make([]string, 0, 3)
+	if len(filter.Exclude) > 0 {
+		parts = append(parts, "🚫 "+gw.JoinWords(filter.Exclude))
+	}
+
+	if len(filter.Require) > 0 {
+		parts = append(parts, "☑️ "+gw.JoinWords(filter.Require))
+	}
+
+	if filter.MatchCategory {
+		parts = append(parts, "(incl. category)")
+	}
+
+	return strings.Join(parts, " ")
+}
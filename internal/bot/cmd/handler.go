@@ -5,6 +5,7 @@ import (
 	"strings"
 
 	"github.com/bwmarrin/discordgo"
+	"github.com/robherley/gw-bot/internal/db/sqlgen"
 )
 
 type Handler interface {
@@ -64,3 +65,36 @@ func UserID(i *discordgo.InteractionCreate) string {
 	}
 	return ""
 }
+
+func SubscriptionSelectOptions(subs []sqlgen.Subscription) []discordgo.SelectMenuOption {
+	options := make([]discordgo.SelectMenuOption, 0, len(subs))
+	for _, sub := range subs {
+		term := sub.Term
+		if term == "" {
+			term = "<empty>"
+		}
+
+		options = append(options, discordgo.SelectMenuOption{
+			Label: term,
+			Value: sub.ID,
+		})
+	}
+	return options
+}
+
+func ModalValues(data discordgo.ModalSubmitInteractionData) map[string]string {
+	values := make(map[string]string)
+	for _, component := range data.Components {
+		row, ok := component.(*discordgo.ActionsRow)
+		if !ok {
+			continue
+		}
+
+		for _, component := range row.Components {
+			if input, ok := component.(*discordgo.TextInput); ok {
+				values[input.CustomID] = strings.TrimSpace(input.Value)
+			}
+		}
+	}
+	return values
+}
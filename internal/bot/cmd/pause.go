@@ -0,0 +1,138 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+	"github.com/robherley/gw-bot/internal/db"
+	"github.com/robherley/gw-bot/internal/db/sqlgen"
+	"github.com/robherley/gw-bot/internal/duration"
+)
+
+func NewPause(db db.DB) Handler {
+	return &Pause{db}
+}
+
+type Pause struct {
+	db db.DB
+}
+
+func (cmd *Pause) Name() string {
+	return "pause"
+}
+
+func (cmd *Pause) Description() string {
+	return "Pause subscription(s), optionally for a while."
+}
+
+func (cmd *Pause) Options() []*discordgo.ApplicationCommandOption {
+	return []*discordgo.ApplicationCommandOption{
+		{
+			Type:        discordgo.ApplicationCommandOptionString,
+			Name:        "for",
+			Description: "How long to pause for, like \"3d\" or \"12h\". Paused until resumed if not set",
+			Required:    false,
+		},
+	}
+}
+
+func (cmd *Pause) Handle(ctx context.Context, s *discordgo.Session, i *discordgo.InteractionCreate) error {
+	userID := UserID(i)
+	if userID == "" {
+		return nil
+	}
+
+	switch i.Type {
+	case discordgo.InteractionApplicationCommand:
+		if err := DeferResponse(s, i); err != nil {
+			return err
+		}
+
+		var pauseFor string
+		for _, option := range i.ApplicationCommandData().Options {
+			if option.Name == "for" {
+				pauseFor = option.StringValue()
+			}
+		}
+
+		if pauseFor != "" {
+			if _, err := duration.Parse(pauseFor); err != nil {
+				return EditResponse(s, i, fmt.Sprintf("⛔ Invalid duration %q, try something like \"3d\" or \"12h\".", pauseFor))
+			}
+		}
+
+		subs, err := cmd.db.FindUserSubscriptions(ctx, userID)
+		if err != nil {
+			return err
+		}
+
+		active := make([]sqlgen.Subscription, 0, len(subs))
+		for _, sub := range subs {
+			if sub.PausedAt == nil {
+				active = append(active, sub)
+			}
+		}
+
+		if len(active) == 0 {
+			return EditResponse(s, i, "ℹ️ You have no active subscriptions to pause.")
+		}
+
+		options := SubscriptionSelectOptions(active)
+
+		return EditResponseComplex(s, i, &discordgo.WebhookEdit{
+			Components: &[]discordgo.MessageComponent{
+				discordgo.ActionsRow{
+					Components: []discordgo.MessageComponent{
+						discordgo.SelectMenu{
+							CustomID:    cmd.Name() + ":select:" + pauseFor,
+							Placeholder: "⏸️ What subscription(s) would you like to pause?",
+							Options:     options,
+							MaxValues:   len(options),
+						},
+					},
+				},
+			},
+		})
+	case discordgo.InteractionMessageComponent:
+		if err := DeferResponse(s, i); err != nil {
+			return err
+		}
+
+		_, args := FromCustomID(i.MessageComponentData().CustomID)
+		subIDs := i.MessageComponentData().Values
+
+		var until *time.Time
+		if len(args) > 1 && args[1] != "" {
+			d, err := duration.Parse(args[1])
+			if err != nil {
+				return err
+			}
+			t := time.Now().Add(d).UTC()
+			until = &t
+		}
+
+		if err := cmd.db.PauseUserSubscriptions(ctx, sqlgen.PauseUserSubscriptionsParams{
+			PausedUntil: until,
+			UserID:      userID,
+			Ids:         subIDs,
+		}); err != nil {
+			return err
+		}
+
+		builder := strings.Builder{}
+		builder.WriteString(fmt.Sprintf("⏸️ Paused %d subscription(s)", len(subIDs)))
+		if until != nil {
+			builder.WriteString(fmt.Sprintf(" until <t:%d:f>", until.Unix()))
+		} else {
+			builder.WriteString(", use `/resume` to start receiving notifications again")
+		}
+		builder.WriteString(".")
+
+		return EditResponse(s, i, builder.String())
+	default:
+		return nil
+	}
+}
@@ -28,6 +28,10 @@ func (cmd *ping) Handle(ctx context.Context, s *discordgo.Session, i *discordgo.
 		return nil
 	}
 
+	if err := DeferResponse(s, i); err != nil {
+		return err
+	}
+
 	user := "unknown"
 	if i.User != nil {
 		user = i.User.String()
@@ -48,10 +52,5 @@ func (cmd *ping) Handle(ctx context.Context, s *discordgo.Session, i *discordgo.
 		return err
 	}
 
-	return s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
-		Type: discordgo.InteractionResponseChannelMessageWithSource,
-		Data: &discordgo.InteractionResponseData{
-			Content: fmt.Sprintf("🏓 pong!\n```json\n%s\n```", bytes),
-		},
-	})
+	return EditResponse(s, i, fmt.Sprintf("🏓 pong!\n```json\n%s\n```", bytes))
 }
@@ -0,0 +1,136 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+
+	"github.com/bwmarrin/discordgo"
+	"github.com/robherley/gw-bot/internal/db"
+	"github.com/robherley/gw-bot/internal/db/sqlgen"
+)
+
+// MaxPrioritySubscriptions is how many subscriptions a user can have searched as often as possible.
+const MaxPrioritySubscriptions = 3
+
+func NewPriority(db db.DB) Handler {
+	return &Priority{db}
+}
+
+type Priority struct {
+	db db.DB
+}
+
+func (cmd *Priority) Name() string {
+	return "priority"
+}
+
+func (cmd *Priority) Description() string {
+	return "Search a subscription for new items as often as possible."
+}
+
+func (cmd *Priority) Options() []*discordgo.ApplicationCommandOption {
+	return []*discordgo.ApplicationCommandOption{
+		{
+			Type:         discordgo.ApplicationCommandOptionString,
+			Name:         "subscription",
+			Description:  "The subscription to change",
+			Required:     true,
+			Autocomplete: true,
+		},
+		{
+			Type:        discordgo.ApplicationCommandOptionBoolean,
+			Name:        "enabled",
+			Description: "Turn priority on or off, subscriptions that rarely find new items are searched less often",
+			Required:    true,
+		},
+	}
+}
+
+func (cmd *Priority) Handle(ctx context.Context, s *discordgo.Session, i *discordgo.InteractionCreate) error {
+	userID := UserID(i)
+	if userID == "" {
+		return nil
+	}
+
+	switch i.Type {
+	case discordgo.InteractionApplicationCommand:
+		if err := DeferResponse(s, i); err != nil {
+			return err
+		}
+
+		var (
+			subID   string
+			enabled bool
+		)
+
+		for _, option := range i.ApplicationCommandData().Options {
+			switch option.Name {
+			case "subscription":
+				subID = option.StringValue()
+			case "enabled":
+				enabled = option.BoolValue()
+			}
+		}
+
+		subs, err := cmd.db.FindUserSubscriptions(ctx, userID)
+		if err != nil {
+			return err
+		}
+
+		var sub *sqlgen.Subscription
+		priorities := 0
+		for _, candidate := range subs {
+			if candidate.ID == subID {
+				sub = &candidate
+			} else if candidate.Priority {
+				priorities++
+			}
+		}
+
+		if sub == nil {
+			return EditResponse(s, i, "⛔ Unknown subscription, please pick one from the list.")
+		}
+
+		if enabled && priorities >= MaxPrioritySubscriptions {
+			return EditResponse(s, i, fmt.Sprintf("⛔ Only up to %d subscriptions can have priority, turn it off for another one first.", MaxPrioritySubscriptions))
+		}
+
+		// the subscription is searched again right away, and with priority it starts over from the shortest interval
+		// instead of where it backed off to
+		pollInterval := sub.PollInterval
+		if enabled {
+			pollInterval = 0
+		}
+
+		if err := cmd.db.SetSubscriptionPriority(ctx, sqlgen.SetSubscriptionPriorityParams{
+			Priority:     enabled,
+			PollInterval: pollInterval,
+			ID:           sub.ID,
+			UserID:       userID,
+		}); err != nil {
+			return err
+		}
+
+		slog.Info("set subscription priority", "user_id", userID, "subscription_id", sub.ID, "priority", enabled)
+
+		if enabled {
+			return EditResponse(s, i, fmt.Sprintf("⚡ %q will be searched for new items as often as possible.", sub.Term))
+		}
+		return EditResponse(s, i, fmt.Sprintf("✅ %q no longer has priority, it's searched less often while it doesn't find new items.", sub.Term))
+	case discordgo.InteractionApplicationCommandAutocomplete:
+		option := FocusedOption(i)
+		if option == nil || option.Name != "subscription" {
+			return RespondChoices(s, i, nil)
+		}
+
+		subs, err := cmd.db.FindUserSubscriptions(ctx, userID)
+		if err != nil {
+			return err
+		}
+
+		return RespondChoices(s, i, SubscriptionChoices(subs, option.StringValue()))
+	default:
+		return nil
+	}
+}
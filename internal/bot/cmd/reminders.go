@@ -0,0 +1,80 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/robherley/gw-bot/internal/duration"
+)
+
+const (
+	// DefaultReminders is ten minutes before an item ends, in the format stored on subscriptions.
+	DefaultReminders = "10"
+	MaxReminders     = 5
+	MaxReminder      = 7 * 24 * time.Hour
+)
+
+// ParseReminders parses comma separated durations like "24h, 1h, 5m" into the minutes stored on subscriptions,
+// largest first.
+func ParseReminders(s string) (string, error) {
+	minutes := make([]int64, 0)
+	for _, part := range strings.Split(s, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+
+		// plain numbers are minutes, like the old notify option
+		if _, err := strconv.Atoi(part); err == nil {
+			part += "m"
+		}
+
+		d, err := duration.Parse(part)
+		if err != nil {
+			return "", fmt.Errorf("invalid reminder %q, try something like \"1h\" or \"5m\"", part)
+		}
+
+		if d < time.Minute || d > MaxReminder {
+			return "", fmt.Errorf("reminder %q must be between 1m and %s", part, duration.Format(MaxReminder))
+		}
+
+		if m := int64(d / time.Minute); !slices.Contains(minutes, m) {
+			minutes = append(minutes, m)
+		}
+	}
+
+	if len(minutes) == 0 {
+		return "", errors.New("at least one reminder is needed")
+	}
+
+	if len(minutes) > MaxReminders {
+		return "", fmt.Errorf("only up to %d reminders are allowed", MaxReminders)
+	}
+
+	slices.Sort(minutes)
+	slices.Reverse(minutes)
+
+	parts := make([]string, 0, len(minutes))
+	for _, m := range minutes {
+		parts = append(parts, strconv.FormatInt(m, 10))
+	}
+
+	return strings.Join(parts, ","), nil
+}
+
+// FormatReminders formats the reminders stored on a subscription, like "1d, 1h, 5m".
+func FormatReminders(reminders string) string {
+	parts := make([]string, 0)
+	for _, part := range strings.Split(reminders, ",") {
+		m, err := strconv.ParseInt(part, 10, 64)
+		if err != nil {
+			continue
+		}
+		parts = append(parts, duration.Format(time.Duration(m)*time.Minute))
+	}
+	return strings.Join(parts, ", ")
+}
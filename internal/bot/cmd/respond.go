@@ -0,0 +1,89 @@
+package cmd
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"log/slog"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+// DeferResponse acknowledges an interaction right away, so the handler has up to 15 minutes to respond with
+// EditResponse instead of discord's 3 second window.
+func DeferResponse(s *discordgo.Session, i *discordgo.InteractionCreate) error {
+	return s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+		Type: discordgo.InteractionResponseDeferredChannelMessageWithSource,
+	})
+}
+
+// DeferEphemeralResponse is like DeferResponse, but only the user that invoked the interaction can see the response.
+func DeferEphemeralResponse(s *discordgo.Session, i *discordgo.InteractionCreate) error {
+	return s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+		Type: discordgo.InteractionResponseDeferredChannelMessageWithSource,
+		Data: &discordgo.InteractionResponseData{
+			Flags: discordgo.MessageFlagsEphemeral,
+		},
+	})
+}
+
+// DeferUpdateResponse acknowledges a component interaction, so EditResponse updates the message with the component
+// instead of sending a new one.
+func DeferUpdateResponse(s *discordgo.Session, i *discordgo.InteractionCreate) error {
+	return s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+		Type: discordgo.InteractionResponseDeferredMessageUpdate,
+	})
+}
+
+// EditResponse sets the content of a deferred response.
+func EditResponse(s *discordgo.Session, i *discordgo.InteractionCreate, content string) error {
+	return EditResponseComplex(s, i, &discordgo.WebhookEdit{
+		Content: &content,
+	})
+}
+
+func EditResponseComplex(s *discordgo.Session, i *discordgo.InteractionCreate, edit *discordgo.WebhookEdit) error {
+	_, err := s.InteractionResponseEdit(i.Interaction, edit)
+	return err
+}
+
+// NewErrorID returns a short id to tell the user about a failed interaction, so it can be found in the logs without
+// showing them the error itself.
+func NewErrorID() string {
+	b := make([]byte, 4)
+	_, _ = rand.Read(b)
+	return hex.EncodeToString(b)
+}
+
+// RespondError lets the user know their interaction failed with an ephemeral message, referencing the error by id
+// (see NewErrorID). If the interaction was already deferred, the pending response is replaced since its visibility
+// can't be changed.
+func RespondError(s *discordgo.Session, i *discordgo.InteractionCreate, errorID string) error {
+	content := "⛔ Something went wrong, please try again later. If it keeps happening, mention error `" + errorID + "`."
+
+	respErr := s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+		Type: discordgo.InteractionResponseChannelMessageWithSource,
+		Data: &discordgo.InteractionResponseData{
+			Content: content,
+			Flags:   discordgo.MessageFlagsEphemeral,
+		},
+	})
+	if respErr == nil {
+		return nil
+	}
+
+	var restErr *discordgo.RESTError
+	if !errors.As(respErr, &restErr) || restErr.Message == nil || restErr.Message.Code != discordgo.ErrCodeInteractionHasAlreadyBeenAcknowledged {
+		return respErr
+	}
+
+	if err := s.InteractionResponseDelete(i.Interaction); err != nil {
+		slog.Warn("failed to delete deferred response", "error", err)
+	}
+
+	_, err := s.FollowupMessageCreate(i.Interaction, true, &discordgo.WebhookParams{
+		Content: content,
+		Flags:   discordgo.MessageFlagsEphemeral,
+	})
+	return err
+}
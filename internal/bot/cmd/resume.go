@@ -0,0 +1,103 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+
+	"github.com/bwmarrin/discordgo"
+	"github.com/robherley/gw-bot/internal/db"
+	"github.com/robherley/gw-bot/internal/db/sqlgen"
+	"github.com/robherley/gw-bot/internal/tracker"
+)
+
+func NewResume(db db.DB, tracker *tracker.Tracker) Handler {
+	return &Resume{db, tracker}
+}
+
+type Resume struct {
+	db      db.DB
+	tracker *tracker.Tracker
+}
+
+func (cmd *Resume) Name() string {
+	return "resume"
+}
+
+func (cmd *Resume) Description() string {
+	return "Resume paused subscription(s)."
+}
+
+func (cmd *Resume) Handle(ctx context.Context, s *discordgo.Session, i *discordgo.InteractionCreate) error {
+	userID := UserID(i)
+	if userID == "" {
+		return nil
+	}
+
+	subs, err := cmd.db.FindUserSubscriptions(ctx, userID)
+	if err != nil {
+		return err
+	}
+
+	paused := make([]sqlgen.Subscription, 0, len(subs))
+	for _, sub := range subs {
+		if sub.PausedAt != nil {
+			paused = append(paused, sub)
+		}
+	}
+
+	switch i.Type {
+	case discordgo.InteractionApplicationCommand:
+		if err := DeferResponse(s, i); err != nil {
+			return err
+		}
+
+		if len(paused) == 0 {
+			return EditResponse(s, i, "ℹ️ You have no paused subscriptions to resume.")
+		}
+
+		options := SubscriptionSelectOptions(paused)
+
+		return EditResponseComplex(s, i, &discordgo.WebhookEdit{
+			Components: &[]discordgo.MessageComponent{
+				discordgo.ActionsRow{
+					Components: []discordgo.MessageComponent{
+						discordgo.SelectMenu{
+							CustomID:    cmd.Name() + ":select",
+							Placeholder: "▶️ What subscription(s) would you like to resume?",
+							Options:     options,
+							MaxValues:   len(options),
+						},
+					},
+				},
+			},
+		})
+	case discordgo.InteractionMessageComponent:
+		if err := DeferResponse(s, i); err != nil {
+			return err
+		}
+
+		resumed := 0
+		for _, subID := range i.MessageComponentData().Values {
+			for _, sub := range paused {
+				if sub.ID != subID {
+					continue
+				}
+
+				log := slog.With("subscription_id", sub.ID, "user_id", sub.UserID)
+
+				n, err := cmd.tracker.Resume(ctx, sub)
+				if err != nil {
+					return err
+				}
+
+				log.Info("resumed subscription", "seeded", n)
+				resumed++
+			}
+		}
+
+		return EditResponse(s, i, fmt.Sprintf("▶️ Resumed %d subscription(s)! Only items listed from now on will be notified.", resumed))
+	default:
+		return nil
+	}
+}
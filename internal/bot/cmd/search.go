@@ -0,0 +1,322 @@
+package cmd
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strconv"
+
+	"github.com/bwmarrin/discordgo"
+	"github.com/robherley/gw-bot/internal/db"
+	"github.com/robherley/gw-bot/internal/db/sqlgen"
+	"github.com/robherley/gw-bot/internal/gw"
+	"github.com/robherley/gw-bot/internal/query"
+	"github.com/robherley/gw-bot/internal/tracker"
+)
+
+const (
+	// SearchPageSize is how many items are shown per page of results.
+	SearchPageSize = 5
+
+	SortNewest     = "newest"
+	SortEndingSoon = "ending_soon"
+)
+
+func NewSearch(db db.DB, gw *gw.Client, tracker *tracker.Tracker) Handler {
+	return &Search{db, tracker, &Subscribe{db, gw, tracker}}
+}
+
+// Search runs a one off search. Searches are stored for a day with their results, so the results can be paged through
+// without searching again and subscribed to.
+type Search struct {
+	db        db.DB
+	tracker   *tracker.Tracker
+	subscribe *Subscribe
+}
+
+func (cmd *Search) Name() string {
+	return "search"
+}
+
+func (cmd *Search) Description() string {
+	return "Search for items without subscribing."
+}
+
+func (cmd *Search) Options() []*discordgo.ApplicationCommandOption {
+	options := make([]*discordgo.ApplicationCommandOption, 0)
+	for _, option := range cmd.subscribe.Options() {
+		// reminders are only asked for when subscribing
+		if option.Name != "reminders" {
+			options = append(options, option)
+		}
+	}
+
+	return append(options, &discordgo.ApplicationCommandOption{
+		Type:        discordgo.ApplicationCommandOptionString,
+		Name:        "sort",
+		Description: "How to sort the results, defaults to newest",
+		Required:    false,
+		Choices: []*discordgo.ApplicationCommandOptionChoice{
+			{Name: "Newest", Value: SortNewest},
+			{Name: "Ending soon", Value: SortEndingSoon},
+		},
+	})
+}
+
+func (cmd *Search) Handle(ctx context.Context, s *discordgo.Session, i *discordgo.InteractionCreate) error {
+	userID := UserID(i)
+	if userID == "" {
+		return nil
+	}
+
+	switch i.Type {
+	case discordgo.InteractionApplicationCommand:
+		if err := DeferResponse(s, i); err != nil {
+			return err
+		}
+
+		params := sqlgen.CreateSearchParams{
+			ID:         db.NewID(),
+			UserID:     userID,
+			Descending: true,
+		}
+
+		var filter gw.Filter
+		for _, option := range i.ApplicationCommandData().Options {
+			switch option.Name {
+			case "term":
+				params.Term = option.StringValue()
+			case "min":
+				min := option.IntValue()
+				params.MinPrice = &min
+			case "max":
+				max := option.IntValue()
+				params.MaxPrice = &max
+			case "exclude":
+				filter.Exclude = gw.ParseWords(option.StringValue())
+			case "require":
+				filter.Require = gw.ParseWords(option.StringValue())
+			case "match_category":
+				filter.MatchCategory = option.BoolValue()
+			case "category":
+				category, err := cmd.subscribe.findCategory(ctx, option.StringValue())
+				if err != nil {
+					return err
+				}
+				if category == nil {
+					return EditResponse(s, i, "⛔ Unknown category, please pick one from the list.")
+				}
+				level := int64(category.Level)
+				params.CategoryID = &category.ID
+				params.CategoryLevel = &level
+			case "sort":
+				params.Descending = option.StringValue() != SortEndingSoon
+			}
+		}
+
+		if _, err := query.Parse(params.Term); err != nil {
+			return EditResponse(s, i, fmt.Sprintf("⛔ Invalid term %q: %s", params.Term, err))
+		}
+
+		if params.MinPrice != nil && params.MaxPrice != nil && *params.MinPrice > *params.MaxPrice {
+			return EditResponse(s, i, "⛔ Minimum price must be less than or equal to maximum price.")
+		}
+
+		params.ExcludeWords = gw.JoinWords(filter.Exclude)
+		params.RequireWords = gw.JoinWords(filter.Require)
+		params.FilterCategory = filter.MatchCategory
+
+		search, err := cmd.db.CreateSearch(ctx, params)
+		if err != nil {
+			return err
+		}
+
+		return cmd.showPage(ctx, s, i, search, 0)
+	case discordgo.InteractionApplicationCommandAutocomplete:
+		return cmd.subscribe.Handle(ctx, s, i)
+	case discordgo.InteractionMessageComponent:
+		_, args := FromCustomID(i.MessageComponentData().CustomID)
+		if len(args) < 2 {
+			return nil
+		}
+
+		search, err := cmd.db.FindUserSearch(ctx, sqlgen.FindUserSearchParams{
+			ID:     args[1],
+			UserID: userID,
+		})
+		if errors.Is(err, sql.ErrNoRows) {
+			if err := DeferEphemeralResponse(s, i); err != nil {
+				return err
+			}
+			return EditResponse(s, i, "⛔ This search expired or isn't yours, run `/search` to start a new one.")
+		} else if err != nil {
+			return err
+		}
+
+		switch args[0] {
+		case "page":
+			if err := DeferUpdateResponse(s, i); err != nil {
+				return err
+			}
+
+			page := 0
+			if len(args) > 2 {
+				page, _ = strconv.Atoi(args[2])
+			}
+
+			return cmd.showPage(ctx, s, i, search, page)
+		case "subscribe":
+			if err := DeferResponse(s, i); err != nil {
+				return err
+			}
+
+			return cmd.subscribeTo(ctx, s, i, search)
+		default:
+			return nil
+		}
+	default:
+		return nil
+	}
+}
+
+func (cmd *Search) showPage(ctx context.Context, s *discordgo.Session, i *discordgo.InteractionCreate, search sqlgen.Search, page int) error {
+	items, err := cmd.results(ctx, search)
+	if err != nil {
+		return err
+	}
+
+	if len(items) == 0 {
+		content := fmt.Sprintf("🔎 No items found for %q.", search.Term)
+		return EditResponseComplex(s, i, &discordgo.WebhookEdit{
+			Content:    &content,
+			Embeds:     &[]*discordgo.MessageEmbed{},
+			Components: &[]discordgo.MessageComponent{},
+		})
+	}
+
+	// buttons of an older message can point past the last page
+	pages := (len(items) + SearchPageSize - 1) / SearchPageSize
+	page = max(0, min(page, pages-1))
+
+	start := page * SearchPageSize
+	end := min(start+SearchPageSize, len(items))
+
+	embeds := make([]*discordgo.MessageEmbed, 0, end-start)
+	for _, item := range items[start:end] {
+		embeds = append(embeds, ItemToEmbed(item))
+	}
+
+	content := fmt.Sprintf("🔎 %d item(s) found for %q, page %d of %d", len(items), search.Term, page+1, pages)
+
+	return EditResponseComplex(s, i, &discordgo.WebhookEdit{
+		Content: &content,
+		Embeds:  &embeds,
+		Components: &[]discordgo.MessageComponent{
+			discordgo.ActionsRow{
+				Components: []discordgo.MessageComponent{
+					discordgo.Button{
+						Label:    "Previous",
+						Style:    discordgo.SecondaryButton,
+						CustomID: fmt.Sprintf("%s:page:%s:%d", cmd.Name(), search.ID, page-1),
+						Disabled: page == 0,
+					},
+					discordgo.Button{
+						Label:    "Next",
+						Style:    discordgo.SecondaryButton,
+						CustomID: fmt.Sprintf("%s:page:%s:%d", cmd.Name(), search.ID, page+1),
+						Disabled: page >= pages-1,
+					},
+					discordgo.Button{
+						Label:    "Subscribe to this search",
+						Style:    discordgo.PrimaryButton,
+						CustomID: fmt.Sprintf("%s:subscribe:%s", cmd.Name(), search.ID),
+					},
+				},
+			},
+		},
+	})
+}
+
+// results returns the items found by the search. They're stored the first time, so pages don't change or search again
+// when paging through them.
+func (cmd *Search) results(ctx context.Context, search sqlgen.Search) ([]gw.Item, error) {
+	if search.Results != nil {
+		var items []gw.Item
+		err := json.Unmarshal([]byte(*search.Results), &items)
+		if err == nil {
+			return items, nil
+		}
+
+		slog.Warn("failed to decode search results, searching again", "search_id", search.ID, "error", err)
+	}
+
+	items, err := cmd.tracker.Search(ctx, SearchSubscription(search), gw.WithDescending(search.Descending))
+	if err != nil {
+		return nil, err
+	}
+
+	b, err := json.Marshal(items)
+	if err != nil {
+		return nil, err
+	}
+
+	results := string(b)
+	if err := cmd.db.SetSearchResults(ctx, sqlgen.SetSearchResultsParams{
+		Results: &results,
+		ID:      search.ID,
+	}); err != nil {
+		return nil, err
+	}
+
+	return items, nil
+}
+
+func (cmd *Search) subscribeTo(ctx context.Context, s *discordgo.Session, i *discordgo.InteractionCreate, search sqlgen.Search) error {
+	q, err := query.Parse(search.Term)
+	if err != nil {
+		return err
+	}
+
+	var category *gw.Category
+	if search.CategoryID != nil {
+		category, err = cmd.subscribe.findCategory(ctx, strconv.FormatInt(*search.CategoryID, 10))
+		if err != nil {
+			return err
+		}
+	}
+
+	sub := SearchSubscription(search)
+
+	return cmd.subscribe.create(ctx, s, i, sqlgen.CreateSubscriptionParams{
+		ID:             db.NewID(),
+		UserID:         search.UserID,
+		Term:           search.Term,
+		MinPrice:       search.MinPrice,
+		MaxPrice:       search.MaxPrice,
+		Reminders:      DefaultReminders,
+		CategoryID:     search.CategoryID,
+		CategoryLevel:  search.CategoryLevel,
+		ExcludeWords:   search.ExcludeWords,
+		RequireWords:   search.RequireWords,
+		FilterCategory: search.FilterCategory,
+	}, q, category, gw.FilterFromSubscription(sub))
+}
+
+// SearchSubscription is the subscription a search would be, so it's searched and filtered the same way.
+func SearchSubscription(search sqlgen.Search) sqlgen.Subscription {
+	return sqlgen.Subscription{
+		ID:             search.ID,
+		UserID:         search.UserID,
+		Term:           search.Term,
+		MinPrice:       search.MinPrice,
+		MaxPrice:       search.MaxPrice,
+		CategoryID:     search.CategoryID,
+		CategoryLevel:  search.CategoryLevel,
+		ExcludeWords:   search.ExcludeWords,
+		RequireWords:   search.RequireWords,
+		FilterCategory: search.FilterCategory,
+	}
+}
@@ -0,0 +1,249 @@
+package cmd
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+	"github.com/robherley/gw-bot/internal/db"
+	"github.com/robherley/gw-bot/internal/db/sqlgen"
+	"github.com/robherley/gw-bot/internal/notify"
+)
+
+// Timezones are suggested when autocompleting, any other IANA timezone can still be typed in.
+var Timezones = []string{
+	"America/New_York",
+	"America/Chicago",
+	"America/Denver",
+	"America/Phoenix",
+	"America/Los_Angeles",
+	"America/Anchorage",
+	"Pacific/Honolulu",
+	"America/Puerto_Rico",
+	"America/Halifax",
+	"America/Toronto",
+	"America/Vancouver",
+	"America/Mexico_City",
+	"America/Sao_Paulo",
+	"Europe/London",
+	"Europe/Dublin",
+	"Europe/Paris",
+	"Europe/Berlin",
+	"Europe/Madrid",
+	"Europe/Amsterdam",
+	"Europe/Stockholm",
+	"Europe/Athens",
+	"Asia/Kolkata",
+	"Asia/Singapore",
+	"Asia/Tokyo",
+	"Australia/Sydney",
+	"Pacific/Auckland",
+	"UTC",
+}
+
+func NewSettings(db db.DB) Handler {
+	return &Settings{db}
+}
+
+type Settings struct {
+	db db.DB
+}
+
+func (cmd *Settings) Name() string {
+	return "settings"
+}
+
+func (cmd *Settings) Description() string {
+	return "View or change your timezone, quiet hours and alerts."
+}
+
+func (cmd *Settings) Options() []*discordgo.ApplicationCommandOption {
+	minValue := float64(0)
+	return []*discordgo.ApplicationCommandOption{
+		{
+			Type:         discordgo.ApplicationCommandOptionString,
+			Name:         "timezone",
+			Description:  "Your timezone, like America/New_York",
+			Required:     false,
+			Autocomplete: true,
+		},
+		{
+			Type:        discordgo.ApplicationCommandOptionInteger,
+			Name:        "quiet_start",
+			Description: "Hour of the day that new items start being held, like 22",
+			Required:    false,
+			MinValue:    &minValue,
+			MaxValue:    23,
+		},
+		{
+			Type:        discordgo.ApplicationCommandOptionInteger,
+			Name:        "quiet_end",
+			Description: "Hour of the day that held items are sent, like 7",
+			Required:    false,
+			MinValue:    &minValue,
+			MaxValue:    23,
+		},
+		{
+			Type:        discordgo.ApplicationCommandOptionBoolean,
+			Name:        "quiet_off",
+			Description: "Turn off quiet hours",
+			Required:    false,
+		},
+		{
+			Type:        discordgo.ApplicationCommandOptionBoolean,
+			Name:        "ending_soon_in_quiet_hours",
+			Description: "Still send ending soon alerts during quiet hours, they're skipped otherwise",
+			Required:    false,
+		},
+		{
+			Type:        discordgo.ApplicationCommandOptionInteger,
+			Name:        "bid_jump",
+			Description: "Alert when an item gets this many new bids at once, 0 turns these alerts off",
+			Required:    false,
+			MinValue:    &minValue,
+		},
+		{
+			Type:        discordgo.ApplicationCommandOptionBoolean,
+			Name:        "sold_reports",
+			Description: "Get a reply with the final price after items you were alerted about end",
+			Required:    false,
+		},
+	}
+}
+
+func (cmd *Settings) Handle(ctx context.Context, s *discordgo.Session, i *discordgo.InteractionCreate) error {
+	userID := UserID(i)
+	if userID == "" {
+		return nil
+	}
+
+	switch i.Type {
+	case discordgo.InteractionApplicationCommand:
+		if err := DeferResponse(s, i); err != nil {
+			return err
+		}
+
+		settings, err := cmd.db.FindUserSettings(ctx, userID)
+		if errors.Is(err, sql.ErrNoRows) {
+			settings = sqlgen.UserSetting{UserID: userID}
+		} else if err != nil {
+			return err
+		}
+
+		options := i.ApplicationCommandData().Options
+		for _, option := range options {
+			switch option.Name {
+			case "timezone":
+				name := strings.TrimSpace(option.StringValue())
+				if _, err := time.LoadLocation(name); name == "" || name == "Local" || err != nil {
+					return EditResponse(s, i, fmt.Sprintf("⛔ Unknown timezone %q, try one like \"America/New_York\".", name))
+				}
+				settings.Timezone = name
+			case "quiet_start":
+				start := option.IntValue()
+				settings.QuietStart = &start
+			case "quiet_end":
+				end := option.IntValue()
+				settings.QuietEnd = &end
+			case "ending_soon_in_quiet_hours":
+				settings.EndingSoonInQuietHours = option.BoolValue()
+			case "bid_jump":
+				settings.BidJump = option.IntValue()
+			case "sold_reports":
+				settings.SoldReports = option.BoolValue()
+			}
+		}
+
+		// turning quiet hours off wins over any hours given with it
+		for _, option := range options {
+			if option.Name == "quiet_off" && option.BoolValue() {
+				settings.QuietStart = nil
+				settings.QuietEnd = nil
+			}
+		}
+
+		if (settings.QuietStart == nil) != (settings.QuietEnd == nil) {
+			return EditResponse(s, i, "⛔ Quiet hours need both `quiet_start` and `quiet_end`.")
+		}
+
+		if len(options) > 0 {
+			if err := cmd.db.SetUserPreferences(ctx, sqlgen.SetUserPreferencesParams{
+				UserID:                 userID,
+				Timezone:               settings.Timezone,
+				QuietStart:             settings.QuietStart,
+				QuietEnd:               settings.QuietEnd,
+				EndingSoonInQuietHours: settings.EndingSoonInQuietHours,
+				BidJump:                settings.BidJump,
+				SoldReports:            settings.SoldReports,
+			}); err != nil {
+				return err
+			}
+
+			slog.Info("updated settings", "user_id", userID)
+		}
+
+		return EditResponse(s, i, FormatSettings(settings))
+	case discordgo.InteractionApplicationCommandAutocomplete:
+		option := FocusedOption(i)
+		if option == nil || option.Name != "timezone" {
+			return RespondChoices(s, i, nil)
+		}
+
+		query := strings.ToLower(option.StringValue())
+		choices := make([]*discordgo.ApplicationCommandOptionChoice, 0, MaxAutocompleteChoices)
+		for _, name := range Timezones {
+			if len(choices) == MaxAutocompleteChoices {
+				break
+			}
+
+			if strings.Contains(strings.ToLower(name), query) {
+				choices = append(choices, &discordgo.ApplicationCommandOptionChoice{
+					Name:  name,
+					Value: name,
+				})
+			}
+		}
+
+		return RespondChoices(s, i, choices)
+	default:
+		return nil
+	}
+}
+
+func FormatSettings(settings sqlgen.UserSetting) string {
+	loc := notify.LocationFor(&settings)
+
+	builder := strings.Builder{}
+	builder.WriteString("⚙️ Your settings:\n")
+	builder.WriteString(fmt.Sprintf("- 🌐 Timezone: %s (it's %s)\n", loc, time.Now().In(loc).Format("3:04 PM")))
+
+	if quiet := notify.QuietHoursFor(&settings); quiet != nil {
+		builder.WriteString(fmt.Sprintf("- 🌙 Quiet hours: %02d:00 - %02d:00, new items and item updates are held until quiet hours end\n", quiet.Start, quiet.End))
+		if settings.EndingSoonInQuietHours {
+			builder.WriteString("- ⏰ Ending soon alerts are still sent during quiet hours\n")
+		} else {
+			builder.WriteString("- ⏰ Ending soon alerts are skipped during quiet hours, the items would end before they're over (see `ending_soon_in_quiet_hours`)\n")
+		}
+	} else {
+		builder.WriteString("- 🌙 Quiet hours: off\n")
+	}
+
+	if settings.BidJump > 0 {
+		builder.WriteString(fmt.Sprintf("- 📈 Bid alerts: when an item gets %d or more new bids\n", settings.BidJump))
+	} else {
+		builder.WriteString("- 📈 Bid alerts: off\n")
+	}
+
+	if settings.SoldReports {
+		builder.WriteString("- 🔨 Sold reports: on\n")
+	} else {
+		builder.WriteString("- 🔨 Sold reports: off\n")
+	}
+
+	return builder.String()
+}
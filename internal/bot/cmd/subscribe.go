@@ -5,21 +5,25 @@ import (
 	"errors"
 	"fmt"
 	"log/slog"
+	"strconv"
 
 	"github.com/bwmarrin/discordgo"
 	"github.com/mattn/go-sqlite3"
 	"github.com/robherley/gw-bot/internal/db"
 	"github.com/robherley/gw-bot/internal/db/sqlgen"
 	"github.com/robherley/gw-bot/internal/gw"
+	"github.com/robherley/gw-bot/internal/query"
+	"github.com/robherley/gw-bot/internal/tracker"
 )
 
-func NewSubscribe(db db.DB, gw *gw.Client) Handler {
-	return &Subscribe{db, gw}
+func NewSubscribe(db db.DB, gw *gw.Client, tracker *tracker.Tracker) Handler {
+	return &Subscribe{db, gw, tracker}
 }
 
 type Subscribe struct {
-	db db.DB
-	gw *gw.Client
+	db      db.DB
+	gw      *gw.Client
+	tracker *tracker.Tracker
 }
 
 func (cmd *Subscribe) Name() string {
@@ -33,12 +37,11 @@ func (cmd *Subscribe) Description() string {
 func (cmd *Subscribe) Options() []*discordgo.ApplicationCommandOption {
 	termMinLength := 1
 	termMaxLength := 100
-	notifyMinValue := float64(1)
 	return []*discordgo.ApplicationCommandOption{
 		{
 			Type:        discordgo.ApplicationCommandOptionString,
 			Name:        "term",
-			Description: "What items do you want to look for?",
+			Description: "What items do you want to look for? Supports \"phrases\", (a OR b), -word, price:<40, ends:<2h",
 			MinLength:   &termMinLength,
 			MaxLength:   termMaxLength,
 			Required:    true,
@@ -56,13 +59,36 @@ func (cmd *Subscribe) Options() []*discordgo.ApplicationCommandOption {
 			Required:    false,
 		},
 		{
-			Type:        discordgo.ApplicationCommandOptionInteger,
-			Name:        "notify",
-			Description: "How many minutes before the auction ends to send a notification",
+			Type:        discordgo.ApplicationCommandOptionString,
+			Name:        "reminders",
+			Description: "When to send reminders before the auction ends, like \"24h, 1h, 5m\". Defaults to 10m",
+			Required:    false,
+		},
+		{
+			Type:         discordgo.ApplicationCommandOptionString,
+			Name:         "category",
+			Description:  "Only alert on items in this category",
+			Required:     false,
+			Autocomplete: true,
+		},
+		{
+			Type:        discordgo.ApplicationCommandOptionString,
+			Name:        "exclude",
+			Description: "Comma separated words to ignore items for, like \"cable, lens cap\"",
+			Required:    false,
+		},
+		{
+			Type:        discordgo.ApplicationCommandOptionString,
+			Name:        "require",
+			Description: "Comma separated words that items must have",
+			Required:    false,
+		},
+		{
+			Type:        discordgo.ApplicationCommandOptionBoolean,
+			Name:        "match_category",
+			Description: "Also match exclude/require words against the item category",
 			Required:    false,
-			MinValue:    &notifyMinValue,
 		},
-		// TODO: category
 	}
 }
 
@@ -74,13 +100,20 @@ func (cmd *Subscribe) Handle(ctx context.Context, s *discordgo.Session, i *disco
 			return nil
 		}
 
+		if err := DeferResponse(s, i); err != nil {
+			return err
+		}
+
 		data := i.ApplicationCommandData()
 
 		var (
-			term          string
-			minPrice      *int64
-			maxPrice      *int64
-			notifyMinutes int64 = 10
+			err       error
+			term      string
+			minPrice  *int64
+			maxPrice  *int64
+			reminders = DefaultReminders
+			category  *gw.Category
+			filter    gw.Filter
 		)
 
 		for _, option := range data.Options {
@@ -93,134 +126,161 @@ func (cmd *Subscribe) Handle(ctx context.Context, s *discordgo.Session, i *disco
 			case "max":
 				max := option.IntValue()
 				maxPrice = &max
-			case "notify":
-				notifyMinutes = option.IntValue()
+			case "reminders":
+				reminders, err = ParseReminders(option.StringValue())
+				if err != nil {
+					return EditResponse(s, i, fmt.Sprintf("⛔ %s.", err))
+				}
+			case "exclude":
+				filter.Exclude = gw.ParseWords(option.StringValue())
+			case "require":
+				filter.Require = gw.ParseWords(option.StringValue())
+			case "match_category":
+				filter.MatchCategory = option.BoolValue()
+			case "category":
+				category, err = cmd.findCategory(ctx, option.StringValue())
+				if err != nil {
+					return err
+				}
+				if category == nil {
+					return EditResponse(s, i, "⛔ Unknown category, please pick one from the list.")
+				}
 			}
 		}
 
+		q, err := query.Parse(term)
+		if err != nil {
+			return EditResponse(s, i, fmt.Sprintf("⛔ Invalid term %q: %s", term, err))
+		}
+
 		if minPrice != nil && maxPrice != nil {
 			if *minPrice > *maxPrice {
-				return s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
-					Type: discordgo.InteractionResponseChannelMessageWithSource,
-					Data: &discordgo.InteractionResponseData{
-						Content: "⛔ Minimum price must be less than or equal to maximum price.",
-					},
-				})
+				return EditResponse(s, i, "⛔ Minimum price must be less than or equal to maximum price.")
 			}
 		}
 
-		subs, err := cmd.db.FindUserSubscriptions(ctx, userID)
-		if err != nil {
-			return err
+		params := sqlgen.CreateSubscriptionParams{
+			ID:             db.NewID(),
+			UserID:         userID,
+			Term:           term,
+			MinPrice:       minPrice,
+			MaxPrice:       maxPrice,
+			Reminders:      reminders,
+			ExcludeWords:   gw.JoinWords(filter.Exclude),
+			RequireWords:   gw.JoinWords(filter.Require),
+			FilterCategory: filter.MatchCategory,
 		}
 
-		if len(subs) >= 25 {
-			return s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
-				Type: discordgo.InteractionResponseChannelMessageWithSource,
-				Data: &discordgo.InteractionResponseData{
-					Content: "⛔ You can only have up to 25 subscriptions at a time. Use `/subscriptions` to see your current subscriptions and `/unsubscribe` to remove one.",
-				},
-			})
+		if category != nil {
+			level := int64(category.Level)
+			params.CategoryID = &category.ID
+			params.CategoryLevel = &level
 		}
 
-		sub, err := cmd.db.CreateSubscription(ctx, sqlgen.CreateSubscriptionParams{
-			ID:            db.NewID(),
-			UserID:        userID,
-			Term:          term,
-			MinPrice:      minPrice,
-			MaxPrice:      maxPrice,
-			NotifyMinutes: notifyMinutes,
-		})
-
-		if err != nil {
-			var sqliteErr sqlite3.Error
-			if errors.As(err, &sqliteErr) && errors.Is(sqliteErr.ExtendedCode, sqlite3.ErrConstraintUnique) {
-				return s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
-					Type: discordgo.InteractionResponseChannelMessageWithSource,
-					Data: &discordgo.InteractionResponseData{
-						Content: fmt.Sprintf("⛔ Already subscribed for search: %q.\nSee subscriptions with `/subscriptions` and `/unsubscribe` if you wish to change your configured subscriptions.", term),
-					},
-				})
-			}
-			return err
+		return cmd.create(ctx, s, i, params, q, category, filter)
+	case discordgo.InteractionApplicationCommandAutocomplete:
+		option := FocusedOption(i)
+		if option == nil || option.Name != "category" {
+			return RespondChoices(s, i, nil)
 		}
 
-		log := slog.With("subscription_id", sub.ID, "user_id", sub.UserID)
-		log.Info("created subscription")
-
-		msg := fmt.Sprintf("🔔 Subscribed for term: %q\n", term)
-		if sub.MinPrice != nil || sub.MaxPrice != nil {
-			msg += "\n"
-			if sub.MaxPrice == nil {
-				msg += fmt.Sprintf("Will only alert on items $%d or more", *sub.MinPrice)
-			} else if sub.MinPrice == nil {
-				msg += fmt.Sprintf("Will only alert on items $%d or less", *sub.MaxPrice)
-			} else {
-				msg += fmt.Sprintf("Will only alert on items $%d - $%d", *sub.MinPrice, *sub.MaxPrice)
-			}
-		}
+		return RespondChoices(s, i, CategoryChoices(ctx, cmd.gw, option.StringValue()))
+	default:
+		return nil
+	}
+}
 
-		dm, err := s.UserChannelCreate(userID)
-		if err != nil {
-			return err
-		}
+// create creates the subscription, lets the user know what it alerts on and tracks the items that are already listed.
+func (cmd *Subscribe) create(ctx context.Context, s *discordgo.Session, i *discordgo.InteractionCreate, params sqlgen.CreateSubscriptionParams, q *query.Query, category *gw.Category, filter gw.Filter) error {
+	userID, term := params.UserID, params.Term
 
-		_, err = s.ChannelMessageSend(dm.ID, msg)
-		if err != nil {
-			return err
-		}
+	subs, err := cmd.db.FindUserSubscriptions(ctx, userID)
+	if err != nil {
+		return err
+	}
 
-		n, err := cmd.seedItems(ctx, sub)
-		if err != nil {
-			log.Error("failed to seed items", "error", err)
+	if len(subs) >= 25 {
+		return EditResponse(s, i, "⛔ You can only have up to 25 subscriptions at a time. Use `/subscriptions` to see your current subscriptions and `/unsubscribe` to remove one.")
+	}
+
+	sub, err := cmd.db.CreateSubscription(ctx, params)
+
+	if err != nil {
+		var sqliteErr sqlite3.Error
+		if errors.As(err, &sqliteErr) && errors.Is(sqliteErr.ExtendedCode, sqlite3.ErrConstraintUnique) {
+			return EditResponse(s, i, fmt.Sprintf("⛔ Already subscribed for search: %q.\nSee subscriptions with `/subscriptions` and `/unsubscribe` if you wish to change your configured subscriptions.", term))
 		}
+		return err
+	}
 
-		log.Info("seeded items", "count", n)
+	log := slog.With("subscription_id", sub.ID, "user_id", sub.UserID)
+	log.Info("created subscription")
 
-		return s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
-			Type: discordgo.InteractionResponseChannelMessageWithSource,
-			Data: &discordgo.InteractionResponseData{
-				Content: fmt.Sprintf("✅ Subscribed, <@%s>! You will receive a DM when new items are found.", userID),
-			},
-		})
-	default:
-		return nil
+	msg := fmt.Sprintf("🔔 Subscribed for term: %q\n", term)
+	if !q.IsPlain() {
+		msg += DescribeQuery(q) + "\n"
+	}
+	if sub.MinPrice != nil || sub.MaxPrice != nil {
+		msg += "\n"
+		if sub.MaxPrice == nil {
+			msg += fmt.Sprintf("Will only alert on items $%d or more", *sub.MinPrice)
+		} else if sub.MinPrice == nil {
+			msg += fmt.Sprintf("Will only alert on items $%d or less", *sub.MaxPrice)
+		} else {
+			msg += fmt.Sprintf("Will only alert on items $%d - $%d", *sub.MinPrice, *sub.MaxPrice)
+		}
+	}
+
+	if category != nil {
+		msg += fmt.Sprintf("\nWill only alert on items in %q", category.FullName)
 	}
-}
 
-func (cmd *Subscribe) seedItems(ctx context.Context, sub sqlgen.Subscription) (int, error) {
-	opts := gw.SearchOptionsFromSubscription(sub)
+	msg += fmt.Sprintf("\nWill remind you %s before items end", FormatReminders(sub.Reminders))
 
-	items := map[int64]gw.Item{}
+	if !filter.IsZero() {
+		msg += "\n" + FormatFilter(filter)
+	}
 
-	newestItems, err := cmd.gw.Search(ctx, sub.Term, append(opts, gw.WithDescending(true))...)
+	dm, err := s.UserChannelCreate(userID)
 	if err != nil {
-		return 0, err
+		return err
 	}
 
-	for _, item := range newestItems {
-		items[item.ItemID] = item
+	_, err = s.ChannelMessageSend(dm.ID, msg)
+	if err != nil {
+		return err
 	}
 
-	endingSoonItems, err := cmd.gw.Search(ctx, sub.Term, append(opts, gw.WithDescending(false))...)
+	n, err := cmd.tracker.Seed(ctx, sub)
 	if err != nil {
-		return 0, err
+		log.Error("failed to seed items", "error", err)
 	}
 
-	for _, item := range endingSoonItems {
-		items[item.ItemID] = item
+	log.Info("seeded items", "count", n)
+
+	return EditResponse(s, i, fmt.Sprintf("✅ Subscribed, <@%s>! You will receive a DM when new items are found.", userID))
+}
+
+func (cmd *Subscribe) findCategory(ctx context.Context, value string) (*gw.Category, error) {
+	id, err := strconv.ParseInt(value, 10, 64)
+	if err != nil {
+		return nil, nil
 	}
 
-	for _, item := range items {
-		if item.Ended() {
-			continue
-		}
+	tree, err := cmd.gw.Categories(ctx)
+	if err != nil {
+		return nil, err
+	}
 
-		_, err := cmd.db.CreateItem(ctx, item.NewCreateItemParams(sub))
-		if err != nil {
-			return 0, err
-		}
+	category, ok := tree.Find(id)
+	if !ok {
+		return nil, nil
 	}
 
-	return len(items), nil
+	return category, nil
+}
+
+// DescribeQuery shows what is searched for on ShopGoodwill and what is matched locally.
+func DescribeQuery(q *query.Query) string {
+	return fmt.Sprintf("🔎 Searching for `%s`, alerting on items matching: `%s`", q.Text, q.String())
 }
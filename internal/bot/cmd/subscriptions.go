@@ -2,19 +2,26 @@ package cmd
 
 import (
 	"context"
+	"database/sql"
+	"errors"
+	"log/slog"
 	"strconv"
 	"strings"
 
 	"github.com/bwmarrin/discordgo"
 	"github.com/robherley/gw-bot/internal/db"
+	"github.com/robherley/gw-bot/internal/db/sqlgen"
+	"github.com/robherley/gw-bot/internal/gw"
+	"github.com/robherley/gw-bot/internal/notify"
 )
 
-func NewSubscriptions(db db.DB) Handler {
-	return &Subscriptions{db}
+func NewSubscriptions(db db.DB, gw *gw.Client) Handler {
+	return &Subscriptions{db, gw}
 }
 
 type Subscriptions struct {
 	db db.DB
+	gw *gw.Client
 }
 
 func (cmd *Subscriptions) Name() string {
@@ -35,12 +42,42 @@ func (cmd *Subscriptions) Handle(ctx context.Context, s *discordgo.Session, i *d
 		return nil
 	}
 
+	if err := DeferResponse(s, i); err != nil {
+		return err
+	}
+
 	subs, err := cmd.db.FindUserSubscriptions(ctx, userID)
 	if err != nil {
 		return err
 	}
 
+	// the category tree is only needed for names, so subscriptions can still be listed if it's unavailable
+	categories, err := cmd.gw.Categories(ctx)
+	if err != nil {
+		slog.Warn("failed to fetch categories", "error", err)
+	}
+
+	settings, err := cmd.db.FindUserSettings(ctx, userID)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return err
+	}
+
+	var userSettings *sqlgen.UserSetting
+	if err == nil {
+		userSettings = &settings
+	}
+
 	builder := strings.Builder{}
+	if settings.VacationAt != nil {
+		builder.WriteString("🏖️ You are on vacation")
+		if settings.VacationUntil != nil {
+			builder.WriteString(" until <t:")
+			builder.WriteString(strconv.FormatInt(settings.VacationUntil.Unix(), 10))
+			builder.WriteString(":f>")
+		}
+		builder.WriteString(", all subscriptions are paused.\n")
+	}
+
 	builder.WriteString("You have ")
 	builder.WriteString(strconv.Itoa(len(subs)))
 	builder.WriteString(" subscription(s)")
@@ -67,19 +104,44 @@ func (cmd *Subscriptions) Handle(ctx context.Context, s *discordgo.Session, i *d
 				builder.WriteString(" ")
 			}
 
+			if sub.CategoryID != nil {
+				builder.WriteString(" 📂 ")
+				if category, ok := categories.Find(*sub.CategoryID); ok {
+					builder.WriteString(category.FullName)
+				} else {
+					builder.WriteString(strconv.FormatInt(*sub.CategoryID, 10))
+				}
+			}
+
+			if filter := gw.FilterFromSubscription(sub); !filter.IsZero() {
+				builder.WriteString(" ")
+				builder.WriteString(FormatFilter(filter))
+			}
+
 			builder.WriteString(" ⏲️ ")
-			builder.WriteString(strconv.FormatInt(sub.NotifyMinutes, 10))
-			builder.WriteString("m")
+			builder.WriteString(FormatReminders(sub.Reminders))
+
+			if schedule := notify.ScheduleFor(sub, userSettings); schedule.Delivery != notify.DeliveryInstant {
+				builder.WriteString(" 📬 ")
+				builder.WriteString(string(schedule.Delivery))
+			}
+
+			if sub.Priority {
+				builder.WriteString(" ⚡ priority")
+			}
+
+			if sub.PausedAt != nil {
+				builder.WriteString(" ⏸️ paused")
+				if sub.PausedUntil != nil {
+					builder.WriteString(" until <t:")
+					builder.WriteString(strconv.FormatInt(sub.PausedUntil.Unix(), 10))
+					builder.WriteString(":f>")
+				}
+			}
 
 			builder.WriteString("\n")
 		}
 	}
 
-	return s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
-		Type: discordgo.InteractionResponseChannelMessageWithSource,
-		Data: &discordgo.InteractionResponseData{
-			CustomID: cmd.Name(),
-			Content:  builder.String(),
-		},
-	})
+	return EditResponse(s, i, builder.String())
 }
@@ -35,52 +35,40 @@ func (cmd *Unsubscribe) Handle(ctx context.Context, s *discordgo.Session, i *dis
 			return nil
 		}
 
+		if err := DeferResponse(s, i); err != nil {
+			return err
+		}
+
 		subs, err := cmd.db.FindUserSubscriptions(ctx, userID)
 		if err != nil {
 			return err
 		}
 
 		if len(subs) == 0 {
-			return s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
-				Type: discordgo.InteractionResponseChannelMessageWithSource,
-				Data: &discordgo.InteractionResponseData{
-					Content: "ℹ️ You have no subscriptions to unsubscribe from.",
-				},
-			})
+			return EditResponse(s, i, "ℹ️ You have no subscriptions to unsubscribe from.")
 		}
 
-		options := make([]discordgo.SelectMenuOption, 0, len(subs))
-		for _, sub := range subs {
-			term := sub.Term
-			if term == "" {
-				term = "<empty>"
-			}
-
-			options = append(options, discordgo.SelectMenuOption{
-				Label: term,
-				Value: sub.ID,
-			})
-		}
-
-		return s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
-			Type: discordgo.InteractionResponseChannelMessageWithSource,
-			Data: &discordgo.InteractionResponseData{
-				CustomID: cmd.Name(),
-				Components: []discordgo.MessageComponent{
-					discordgo.ActionsRow{
-						Components: []discordgo.MessageComponent{
-							discordgo.SelectMenu{
-								CustomID:    cmd.Name() + ":remove",
-								Placeholder: "⏹️ What subscription(s) would you like to remove?",
-								Options:     options,
-								MaxValues:   len(options),
-							},
+		options := SubscriptionSelectOptions(subs)
+
+		return EditResponseComplex(s, i, &discordgo.WebhookEdit{
+			Components: &[]discordgo.MessageComponent{
+				discordgo.ActionsRow{
+					Components: []discordgo.MessageComponent{
+						discordgo.SelectMenu{
+							CustomID:    cmd.Name() + ":remove",
+							Placeholder: "⏹️ What subscription(s) would you like to remove?",
+							Options:     options,
+							MaxValues:   len(options),
 						},
 					},
 				},
 			},
 		})
 	case discordgo.InteractionMessageComponent:
+		if err := DeferResponse(s, i); err != nil {
+			return err
+		}
+
 		subIDs := i.MessageComponentData().Values
 		userID := UserID(i)
 
@@ -93,6 +81,18 @@ func (cmd *Unsubscribe) Handle(ctx context.Context, s *discordgo.Session, i *dis
 			return err
 		}
 
+		if err := cmd.db.DeleteWebhooksInSubscriptions(ctx, subIDs); err != nil {
+			return err
+		}
+
+		if err := cmd.db.DeleteQueuedItemsInSubscriptions(ctx, subIDs); err != nil {
+			return err
+		}
+
+		if err := cmd.db.DeleteOutboxMessagesInSubscriptions(ctx, subIDs); err != nil {
+			return err
+		}
+
 		if err := cmd.db.DeleteUserSubscriptions(ctx, sqlgen.DeleteUserSubscriptionsParams{
 			UserID: userID,
 			Ids:    subIDs,
@@ -130,12 +130,7 @@ func (cmd *Unsubscribe) Handle(ctx context.Context, s *discordgo.Session, i *dis
 			return err
 		}
 
-		return s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
-			Type: discordgo.InteractionResponseChannelMessageWithSource,
-			Data: &discordgo.InteractionResponseData{
-				Content: fmt.Sprintf("✅ Unsubscribed from %d term(s)!", len(deleted)),
-			},
-		})
+		return EditResponse(s, i, fmt.Sprintf("✅ Unsubscribed from %d term(s)!", len(deleted)))
 	default:
 		return nil
 	}
@@ -0,0 +1,129 @@
+package cmd
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+	"github.com/robherley/gw-bot/internal/db"
+	"github.com/robherley/gw-bot/internal/db/sqlgen"
+	"github.com/robherley/gw-bot/internal/duration"
+	"github.com/robherley/gw-bot/internal/tracker"
+)
+
+func NewVacation(db db.DB, tracker *tracker.Tracker) Handler {
+	return &Vacation{db, tracker}
+}
+
+type Vacation struct {
+	db      db.DB
+	tracker *tracker.Tracker
+}
+
+func (cmd *Vacation) Name() string {
+	return "vacation"
+}
+
+func (cmd *Vacation) Description() string {
+	return "Pause all of your subscriptions while you're away."
+}
+
+func (cmd *Vacation) Options() []*discordgo.ApplicationCommandOption {
+	return []*discordgo.ApplicationCommandOption{
+		{
+			Type:        discordgo.ApplicationCommandOptionBoolean,
+			Name:        "enabled",
+			Description: "Whether vacation mode is on",
+			Required:    true,
+		},
+		{
+			Type:        discordgo.ApplicationCommandOptionString,
+			Name:        "for",
+			Description: "How long the vacation lasts, like \"1w\" or \"3d\". Lasts until turned off if not set",
+			Required:    false,
+		},
+	}
+}
+
+func (cmd *Vacation) Handle(ctx context.Context, s *discordgo.Session, i *discordgo.InteractionCreate) error {
+	if i.Type != discordgo.InteractionApplicationCommand {
+		return nil
+	}
+
+	userID := UserID(i)
+	if userID == "" {
+		return nil
+	}
+
+	if err := DeferResponse(s, i); err != nil {
+		return err
+	}
+
+	var (
+		enabled     bool
+		vacationFor string
+	)
+
+	for _, option := range i.ApplicationCommandData().Options {
+		switch option.Name {
+		case "enabled":
+			enabled = option.BoolValue()
+		case "for":
+			vacationFor = option.StringValue()
+		}
+	}
+
+	settings, err := cmd.db.FindUserSettings(ctx, userID)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return err
+	}
+
+	log := slog.With("user_id", userID)
+
+	if !enabled {
+		if settings.VacationAt == nil {
+			return EditResponse(s, i, "ℹ️ You are not on vacation.")
+		}
+
+		n, err := cmd.tracker.EndVacation(ctx, userID)
+		if err != nil {
+			return err
+		}
+
+		log.Info("ended vacation", "seeded", n)
+
+		return EditResponse(s, i, "🏠 Welcome back! Your subscriptions are active again, only items listed from now on will be notified.")
+	}
+
+	var until *time.Time
+	if vacationFor != "" {
+		d, err := duration.Parse(vacationFor)
+		if err != nil {
+			return EditResponse(s, i, fmt.Sprintf("⛔ Invalid duration %q, try something like \"1w\" or \"3d\".", vacationFor))
+		}
+		t := time.Now().Add(d).UTC()
+		until = &t
+	}
+
+	if err := cmd.db.StartUserVacation(ctx, sqlgen.StartUserVacationParams{
+		UserID:        userID,
+		VacationUntil: until,
+	}); err != nil {
+		return err
+	}
+
+	log.Info("started vacation", "until", until)
+
+	msg := "🏖️ Enjoy your vacation! All of your subscriptions are paused"
+	if until != nil {
+		msg += fmt.Sprintf(" until <t:%d:f>.", until.Unix())
+	} else {
+		msg += ", use `/vacation enabled:False` when you're back."
+	}
+
+	return EditResponse(s, i, msg)
+}
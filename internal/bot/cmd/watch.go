@@ -0,0 +1,115 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+
+	"github.com/bwmarrin/discordgo"
+	"github.com/robherley/gw-bot/internal/db"
+	"github.com/robherley/gw-bot/internal/db/sqlgen"
+	"github.com/robherley/gw-bot/internal/gw"
+)
+
+func NewWatch(db db.DB, gw *gw.Client) Handler {
+	return &Watch{db, gw}
+}
+
+type Watch struct {
+	db db.DB
+	gw *gw.Client
+}
+
+func (cmd *Watch) Name() string {
+	return "watch"
+}
+
+func (cmd *Watch) Description() string {
+	return "Get reminded before a single item ends."
+}
+
+func (cmd *Watch) Options() []*discordgo.ApplicationCommandOption {
+	return []*discordgo.ApplicationCommandOption{
+		{
+			Type:        discordgo.ApplicationCommandOptionString,
+			Name:        "item",
+			Description: "Item URL like https://shopgoodwill.com/item/123, or just the ID",
+			Required:    true,
+		},
+		{
+			Type:        discordgo.ApplicationCommandOptionString,
+			Name:        "reminders",
+			Description: "When to send reminders before the item ends, like \"24h, 1h, 5m\". Defaults to 10m",
+			Required:    false,
+		},
+	}
+}
+
+func (cmd *Watch) Handle(ctx context.Context, s *discordgo.Session, i *discordgo.InteractionCreate) error {
+	if i.Type != discordgo.InteractionApplicationCommand {
+		return nil
+	}
+
+	userID := UserID(i)
+	if userID == "" {
+		return nil
+	}
+
+	if err := DeferResponse(s, i); err != nil {
+		return err
+	}
+
+	var (
+		err       error
+		rawItem   string
+		reminders = DefaultReminders
+	)
+
+	for _, option := range i.ApplicationCommandData().Options {
+		switch option.Name {
+		case "item":
+			rawItem = option.StringValue()
+		case "reminders":
+			reminders, err = ParseReminders(option.StringValue())
+			if err != nil {
+				return EditResponse(s, i, "⛔ "+err.Error()+".")
+			}
+		}
+	}
+
+	itemID, err := gw.ParseItemID(rawItem)
+	if err != nil {
+		return EditResponse(s, i, fmt.Sprintf("⛔ Invalid item %q, use a link like https://shopgoodwill.com/item/123 or the item ID.", rawItem))
+	}
+
+	item, err := cmd.gw.FindItem(ctx, itemID)
+	if errors.Is(err, gw.ErrNotFound) {
+		return EditResponse(s, i, fmt.Sprintf("⛔ Couldn't find item %d on ShopGoodwill.", itemID))
+	} else if err != nil {
+		return err
+	}
+
+	if item.Ended() {
+		return EditResponse(s, i, fmt.Sprintf("⛔ %q already ended.", item.Title))
+	}
+
+	watched, err := cmd.db.WatchItem(ctx, sqlgen.WatchItemParams{
+		ID:           db.NewID(),
+		UserID:       userID,
+		GoodwillID:   item.ItemID,
+		Title:        item.Title,
+		EndsAt:       item.EndTime,
+		Reminders:    reminders,
+		CurrentPrice: item.CurrentPrice,
+		NumBids:      item.NumBids,
+		BuyNowPrice:  item.BuyNowPrice,
+	})
+	if err != nil {
+		return err
+	}
+
+	slog.Info("watching item", "watched_item_id", watched.ID, "user_id", userID, "goodwill_id", item.ItemID)
+
+	return EditResponse(s, i, fmt.Sprintf("👀 Watching [%s](<%s>), ends <t:%d:R>. Will remind you %s before it ends.", item.Title, item.URL(), item.EndTime.Unix(), FormatReminders(watched.Reminders)))
+}
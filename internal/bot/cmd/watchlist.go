@@ -0,0 +1,177 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/bwmarrin/discordgo"
+	"github.com/robherley/gw-bot/internal/db"
+	"github.com/robherley/gw-bot/internal/db/sqlgen"
+	"github.com/robherley/gw-bot/internal/gw"
+)
+
+const (
+	// MaxSelectOptions is the most options discord allows in a select menu.
+	MaxSelectOptions = 25
+	// MaxMessageLength is the most characters discord allows in a message.
+	MaxMessageLength = 2000
+)
+
+func NewWatchlist(db db.DB) Handler {
+	return &Watchlist{db}
+}
+
+type Watchlist struct {
+	db db.DB
+}
+
+func (cmd *Watchlist) Name() string {
+	return "watchlist"
+}
+
+func (cmd *Watchlist) Description() string {
+	return "View or remove watched items."
+}
+
+func (cmd *Watchlist) Options() []*discordgo.ApplicationCommandOption {
+	return []*discordgo.ApplicationCommandOption{
+		{
+			Type:        discordgo.ApplicationCommandOptionSubCommand,
+			Name:        "list",
+			Description: "View your watched items",
+		},
+		{
+			Type:        discordgo.ApplicationCommandOptionSubCommand,
+			Name:        "remove",
+			Description: "Stop watching item(s)",
+		},
+	}
+}
+
+func (cmd *Watchlist) Handle(ctx context.Context, s *discordgo.Session, i *discordgo.InteractionCreate) error {
+	userID := UserID(i)
+	if userID == "" {
+		return nil
+	}
+
+	switch i.Type {
+	case discordgo.InteractionApplicationCommand:
+		if err := DeferResponse(s, i); err != nil {
+			return err
+		}
+
+		options := i.ApplicationCommandData().Options
+		if len(options) == 0 {
+			return nil
+		}
+
+		watched, err := cmd.db.FindUserWatchedItems(ctx, userID)
+		if err != nil {
+			return err
+		}
+
+		switch options[0].Name {
+		case "list":
+			return EditResponse(s, i, FormatWatchlist(watched))
+		case "remove":
+			return cmd.selectRemove(s, i, watched)
+		default:
+			return nil
+		}
+	case discordgo.InteractionMessageComponent:
+		if err := DeferResponse(s, i); err != nil {
+			return err
+		}
+
+		ids := i.MessageComponentData().Values
+		if err := cmd.db.DeleteUserWatchedItems(ctx, sqlgen.DeleteUserWatchedItemsParams{
+			UserID: userID,
+			Ids:    ids,
+		}); err != nil {
+			return err
+		}
+
+		slog.Info("removed watched items", "user_id", userID, "count", len(ids))
+
+		return EditResponse(s, i, fmt.Sprintf("✅ Stopped watching %d item(s)!", len(ids)))
+	default:
+		return nil
+	}
+}
+
+func (cmd *Watchlist) selectRemove(s *discordgo.Session, i *discordgo.InteractionCreate, watched []sqlgen.WatchedItem) error {
+	if len(watched) == 0 {
+		return EditResponse(s, i, "ℹ️ You have no watched items to remove.")
+	}
+
+	// watched items are ordered by when they end, so the ones left out are the furthest away
+	if len(watched) > MaxSelectOptions {
+		watched = watched[:MaxSelectOptions]
+	}
+
+	options := make([]discordgo.SelectMenuOption, 0, len(watched))
+	for _, w := range watched {
+		options = append(options, discordgo.SelectMenuOption{
+			Label:       truncate(w.Title, 100),
+			Value:       w.ID,
+			Description: fmt.Sprintf("#%d", w.GoodwillID),
+		})
+	}
+
+	return EditResponseComplex(s, i, &discordgo.WebhookEdit{
+		Components: &[]discordgo.MessageComponent{
+			discordgo.ActionsRow{
+				Components: []discordgo.MessageComponent{
+					discordgo.SelectMenu{
+						CustomID:    cmd.Name() + ":remove",
+						Placeholder: "🗑️ What item(s) would you like to stop watching?",
+						Options:     options,
+						MaxValues:   len(options),
+					},
+				},
+			},
+		},
+	})
+}
+
+// FormatWatchlist lists the watched items in a single message, leaving out the ones that don't fit with an "…and N
+// more" line.
+func FormatWatchlist(watched []sqlgen.WatchedItem) string {
+	builder := strings.Builder{}
+	builder.WriteString(fmt.Sprintf("👀 You are watching %d item(s)", len(watched)))
+
+	if len(watched) > 0 {
+		builder.WriteString(":\n")
+		length := utf8.RuneCountInString(builder.String())
+		for i, w := range watched {
+			line := fmt.Sprintf("- [%s](<%s>) ends <t:%d:R> ⏲️ %s\n", w.Title, gw.ItemURL(w.GoodwillID), w.EndsAt.Unix(), FormatReminders(w.Reminders))
+
+			// leave room to say how many are left out, unless this is the last one
+			overflow := ""
+			if i < len(watched)-1 {
+				overflow = fmt.Sprintf("…and %d more", len(watched)-i-1)
+			}
+
+			if length+utf8.RuneCountInString(line+overflow) > MaxMessageLength {
+				builder.WriteString(fmt.Sprintf("…and %d more", len(watched)-i))
+				break
+			}
+
+			builder.WriteString(line)
+			length += utf8.RuneCountInString(line)
+		}
+	}
+
+	return builder.String()
+}
+
+func truncate(s string, n int) string {
+	runes := []rune(s)
+	if len(runes) <= n {
+		return s
+	}
+	return string(runes[:n-1]) + "…"
+}
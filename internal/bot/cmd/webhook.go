@@ -0,0 +1,344 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/url"
+	"strings"
+
+	"github.com/bwmarrin/discordgo"
+	"github.com/robherley/gw-bot/internal/db"
+	"github.com/robherley/gw-bot/internal/db/sqlgen"
+	"github.com/robherley/gw-bot/internal/notify"
+)
+
+func NewWebhook(db db.DB, notifier *notify.Webhook) Handler {
+	return &Webhook{db, notifier}
+}
+
+type Webhook struct {
+	db       db.DB
+	notifier *notify.Webhook
+}
+
+func (cmd *Webhook) Name() string {
+	return "webhook"
+}
+
+func (cmd *Webhook) Description() string {
+	return "Send alerts to your own HTTP endpoint."
+}
+
+func (cmd *Webhook) Options() []*discordgo.ApplicationCommandOption {
+	return []*discordgo.ApplicationCommandOption{
+		{
+			Type:        discordgo.ApplicationCommandOptionSubCommand,
+			Name:        "set",
+			Description: "Add a webhook, or replace the one for the same subscription",
+			Options: []*discordgo.ApplicationCommandOption{
+				{
+					Type:        discordgo.ApplicationCommandOptionString,
+					Name:        "url",
+					Description: "Where to POST alerts to",
+					Required:    true,
+				},
+				{
+					Type:         discordgo.ApplicationCommandOptionString,
+					Name:         "subscription",
+					Description:  "Only send alerts for this subscription. Sends alerts for all subscriptions if not set",
+					Required:     false,
+					Autocomplete: true,
+				},
+			},
+		},
+		{
+			Type:        discordgo.ApplicationCommandOptionSubCommand,
+			Name:        "list",
+			Description: "View your webhooks",
+		},
+		{
+			Type:        discordgo.ApplicationCommandOptionSubCommand,
+			Name:        "remove",
+			Description: "Remove webhook(s)",
+		},
+		{
+			Type:        discordgo.ApplicationCommandOptionSubCommand,
+			Name:        "test",
+			Description: "Send a test alert to all of your webhooks",
+		},
+	}
+}
+
+func (cmd *Webhook) Handle(ctx context.Context, s *discordgo.Session, i *discordgo.InteractionCreate) error {
+	userID := UserID(i)
+	if userID == "" {
+		return nil
+	}
+
+	switch i.Type {
+	case discordgo.InteractionApplicationCommand:
+		// webhook URLs and secrets shouldn't be shared with the channel
+		if err := DeferEphemeralResponse(s, i); err != nil {
+			return err
+		}
+
+		options := i.ApplicationCommandData().Options
+		if len(options) == 0 {
+			return nil
+		}
+
+		switch options[0].Name {
+		case "set":
+			return cmd.set(ctx, s, i, userID, options[0].Options)
+		case "list":
+			return cmd.list(ctx, s, i, userID)
+		case "remove":
+			return cmd.selectRemove(ctx, s, i, userID)
+		case "test":
+			return cmd.test(ctx, s, i, userID)
+		default:
+			return nil
+		}
+	case discordgo.InteractionApplicationCommandAutocomplete:
+		option := FocusedOption(i)
+		if option == nil || option.Name != "subscription" {
+			return RespondChoices(s, i, nil)
+		}
+
+		subs, err := cmd.db.FindUserSubscriptions(ctx, userID)
+		if err != nil {
+			return err
+		}
+
+		return RespondChoices(s, i, SubscriptionChoices(subs, option.StringValue()))
+	case discordgo.InteractionMessageComponent:
+		if err := DeferEphemeralResponse(s, i); err != nil {
+			return err
+		}
+
+		ids := i.MessageComponentData().Values
+		if err := cmd.db.DeleteUserWebhooks(ctx, sqlgen.DeleteUserWebhooksParams{
+			UserID: userID,
+			Ids:    ids,
+		}); err != nil {
+			return err
+		}
+
+		slog.Info("removed webhooks", "user_id", userID, "count", len(ids))
+
+		return EditResponse(s, i, fmt.Sprintf("✅ Removed %d webhook(s)!", len(ids)))
+	default:
+		return nil
+	}
+}
+
+func (cmd *Webhook) set(ctx context.Context, s *discordgo.Session, i *discordgo.InteractionCreate, userID string, options []*discordgo.ApplicationCommandInteractionDataOption) error {
+	var rawURL, subID string
+	for _, option := range options {
+		switch option.Name {
+		case "url":
+			rawURL = strings.TrimSpace(option.StringValue())
+		case "subscription":
+			subID = option.StringValue()
+		}
+	}
+
+	u, err := url.Parse(rawURL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return EditResponse(s, i, fmt.Sprintf("⛔ Invalid URL %q, it must start with http:// or https://.", rawURL))
+	}
+
+	if err := notify.CheckWebhookHost(ctx, u.Hostname()); err != nil {
+		if errors.Is(err, notify.ErrWebhookAddress) {
+			return EditResponse(s, i, fmt.Sprintf("⛔ Invalid URL %q, webhooks can't be sent to local or private addresses.", rawURL))
+		}
+		return EditResponse(s, i, fmt.Sprintf("⛔ Couldn't find the host of %q, please check the URL.", rawURL))
+	}
+
+	scope := "all subscriptions"
+	if subID != "" {
+		sub, err := cmd.findUserSubscription(ctx, userID, subID)
+		if err != nil {
+			return err
+		}
+
+		if sub == nil {
+			return EditResponse(s, i, "⛔ Unknown subscription, please pick one from the list.")
+		}
+
+		scope = fmt.Sprintf("%q", sub.Term)
+	}
+
+	secret, err := notify.NewWebhookSecret()
+	if err != nil {
+		return err
+	}
+
+	hook, err := cmd.db.UpsertWebhook(ctx, sqlgen.UpsertWebhookParams{
+		ID:             db.NewID(),
+		UserID:         userID,
+		SubscriptionID: subID,
+		Url:            u.String(),
+		Secret:         secret,
+	})
+	if err != nil {
+		return err
+	}
+
+	slog.Info("set webhook", "webhook_id", hook.ID, "user_id", userID, "subscription_id", subID)
+
+	msg := fmt.Sprintf("🪝 Alerts for %s will be sent to <%s>\n", scope, hook.Url)
+	msg += fmt.Sprintf("Payloads are signed with HMAC-SHA256 in the `%s` header using this secret, it won't be shown again:\n`%s`", notify.WebhookSignatureHeader, hook.Secret)
+
+	return EditResponse(s, i, msg)
+}
+
+func (cmd *Webhook) list(ctx context.Context, s *discordgo.Session, i *discordgo.InteractionCreate, userID string) error {
+	hooks, err := cmd.db.FindUserWebhooks(ctx, userID)
+	if err != nil {
+		return err
+	}
+
+	if len(hooks) == 0 {
+		return EditResponse(s, i, "ℹ️ You have no webhooks, add one with `/webhook set`.")
+	}
+
+	subs, err := cmd.db.FindUserSubscriptions(ctx, userID)
+	if err != nil {
+		return err
+	}
+
+	builder := strings.Builder{}
+	builder.WriteString(fmt.Sprintf("You have %d webhook(s):\n", len(hooks)))
+	for _, hook := range hooks {
+		builder.WriteString("- <")
+		builder.WriteString(hook.Url)
+		builder.WriteString("> for ")
+		builder.WriteString(webhookScope(hook, subs))
+		builder.WriteString("\n")
+	}
+
+	return EditResponse(s, i, builder.String())
+}
+
+func (cmd *Webhook) selectRemove(ctx context.Context, s *discordgo.Session, i *discordgo.InteractionCreate, userID string) error {
+	hooks, err := cmd.db.FindUserWebhooks(ctx, userID)
+	if err != nil {
+		return err
+	}
+
+	if len(hooks) == 0 {
+		return EditResponse(s, i, "ℹ️ You have no webhooks to remove.")
+	}
+
+	subs, err := cmd.db.FindUserSubscriptions(ctx, userID)
+	if err != nil {
+		return err
+	}
+
+	options := make([]discordgo.SelectMenuOption, 0, len(hooks))
+	for _, hook := range hooks {
+		label := hook.Url
+		if u, err := url.Parse(hook.Url); err == nil {
+			label = u.Host
+		}
+
+		description := "For " + webhookScope(hook, subs)
+		if runes := []rune(description); len(runes) > 100 {
+			description = string(runes[:99]) + "…"
+		}
+
+		options = append(options, discordgo.SelectMenuOption{
+			Label:       label,
+			Description: description,
+			Value:       hook.ID,
+		})
+	}
+
+	return EditResponseComplex(s, i, &discordgo.WebhookEdit{
+		Components: &[]discordgo.MessageComponent{
+			discordgo.ActionsRow{
+				Components: []discordgo.MessageComponent{
+					discordgo.SelectMenu{
+						CustomID:    cmd.Name() + ":remove",
+						Placeholder: "🪝 What webhook(s) would you like to remove?",
+						Options:     options,
+						MaxValues:   len(options),
+					},
+				},
+			},
+		},
+	})
+}
+
+func (cmd *Webhook) test(ctx context.Context, s *discordgo.Session, i *discordgo.InteractionCreate, userID string) error {
+	hooks, err := cmd.db.FindUserWebhooks(ctx, userID)
+	if err != nil {
+		return err
+	}
+
+	if len(hooks) == 0 {
+		return EditResponse(s, i, "ℹ️ You have no webhooks to test, add one with `/webhook set`.")
+	}
+
+	payload := notify.NewWebhookPayload(notify.Notification{
+		Kind: notify.KindTest,
+	})
+
+	builder := strings.Builder{}
+	for _, hook := range hooks {
+		if err := cmd.notifier.Deliver(ctx, hook, db.NewID(), payload); err != nil {
+			slog.Warn("failed to test webhook", "webhook_id", hook.ID, "user_id", userID, "error", err)
+			builder.WriteString(fmt.Sprintf("⛔ <%s>: %s\n", hook.Url, webhookFailure(err)))
+		} else {
+			builder.WriteString(fmt.Sprintf("✅ <%s>\n", hook.Url))
+		}
+	}
+
+	return EditResponse(s, i, builder.String())
+}
+
+// webhookFailure describes why a delivery failed without the details of the connection, like what's listening on
+// the host.
+func webhookFailure(err error) string {
+	var statusErr *notify.WebhookStatusError
+	switch {
+	case errors.As(err, &statusErr):
+		return fmt.Sprintf("responded with status %d", statusErr.StatusCode)
+	case errors.Is(err, notify.ErrWebhookAddress):
+		return "local or private addresses aren't allowed"
+	default:
+		return "couldn't be reached"
+	}
+}
+
+func (cmd *Webhook) findUserSubscription(ctx context.Context, userID, subID string) (*sqlgen.Subscription, error) {
+	subs, err := cmd.db.FindUserSubscriptions(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, sub := range subs {
+		if sub.ID == subID {
+			return &sub, nil
+		}
+	}
+
+	return nil, nil
+}
+
+func webhookScope(hook sqlgen.Webhook, subs []sqlgen.Subscription) string {
+	if hook.SubscriptionID == "" {
+		return "all subscriptions"
+	}
+
+	for _, sub := range subs {
+		if sub.ID == hook.SubscriptionID {
+			return fmt.Sprintf("%q", sub.Term)
+		}
+	}
+
+	return "a removed subscription"
+}
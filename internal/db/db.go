@@ -12,4 +12,6 @@ type DB interface {
 
 	Close() error
 	Migrate(context.Context, fs.FS) error
+	// WithTx runs fn in a transaction, which is rolled back if fn returns an error.
+	WithTx(context.Context, func(sqlgen.Querier) error) error
 }
@@ -4,6 +4,7 @@ import (
 	"context"
 	"database/sql"
 	_ "embed"
+	"errors"
 	"io/fs"
 
 	_ "github.com/mattn/go-sqlite3"
@@ -34,3 +35,16 @@ func (s *SQLite) Migrate(ctx context.Context, migrations fs.FS) error {
 
 	return goose.Up(s.DB, "database/migrations")
 }
+
+func (s *SQLite) WithTx(ctx context.Context, fn func(sqlgen.Querier) error) error {
+	tx, err := s.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+
+	if err := fn(s.Queries.WithTx(tx)); err != nil {
+		return errors.Join(err, tx.Rollback())
+	}
+
+	return tx.Commit()
+}
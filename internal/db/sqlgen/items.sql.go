@@ -12,9 +12,9 @@ import (
 )
 
 const createItem = `-- name: CreateItem :one
-INSERT INTO items (id, subscription_id, goodwill_id, created_at, started_at, ends_at)
-VALUES (?, ?, ?, CURRENT_TIMESTAMP, ?, ?)
-RETURNING id, subscription_id, goodwill_id, created_at, started_at, ends_at, sent_final
+INSERT INTO items (id, subscription_id, goodwill_id, created_at, started_at, ends_at, current_price, num_bids, buy_now_price, checked_at)
+VALUES (?, ?, ?, CURRENT_TIMESTAMP, ?, ?, ?, ?, ?, CURRENT_TIMESTAMP)
+RETURNING id, subscription_id, goodwill_id, created_at, started_at, ends_at, current_price, num_bids, buy_now_price, checked_at
 `
 
 type CreateItemParams struct {
@@ -23,6 +23,9 @@ type CreateItemParams struct {
 	GoodwillID     int64
 	StartedAt      time.Time
 	EndsAt         time.Time
+	CurrentPrice   float64
+	NumBids        int64
+	BuyNowPrice    float64
 }
 
 func (q *Queries) CreateItem(ctx context.Context, arg CreateItemParams) (Item, error) {
@@ -32,6 +35,9 @@ func (q *Queries) CreateItem(ctx context.Context, arg CreateItemParams) (Item, e
 		arg.GoodwillID,
 		arg.StartedAt,
 		arg.EndsAt,
+		arg.CurrentPrice,
+		arg.NumBids,
+		arg.BuyNowPrice,
 	)
 	var i Item
 	err := row.Scan(
@@ -41,7 +47,10 @@ func (q *Queries) CreateItem(ctx context.Context, arg CreateItemParams) (Item, e
 		&i.CreatedAt,
 		&i.StartedAt,
 		&i.EndsAt,
-		&i.SentFinal,
+		&i.CurrentPrice,
+		&i.NumBids,
+		&i.BuyNowPrice,
+		&i.CheckedAt,
 	)
 	return i, err
 }
@@ -49,6 +58,14 @@ func (q *Queries) CreateItem(ctx context.Context, arg CreateItemParams) (Item, e
 const deleteExpiredItems = `-- name: DeleteExpiredItems :exec
 DELETE FROM items
 WHERE ends_at < datetime('now', '-1 day')
+  AND (goodwill_id IN (SELECT goodwill_id FROM item_results)
+    OR goodwill_id IN (SELECT goodwill_id FROM item_result_failures WHERE final)
+    OR goodwill_id NOT IN (
+      SELECT am.goodwill_id FROM alert_messages am
+      JOIN user_settings us ON us.user_id = am.user_id
+      WHERE us.sold_reports = TRUE
+    )
+    OR ends_at < datetime('now', '-7 days'))
 `
 
 func (q *Queries) DeleteExpiredItems(ctx context.Context) error {
@@ -76,30 +93,56 @@ func (q *Queries) DeleteItemsInSubscriptions(ctx context.Context, ids []string)
 	return err
 }
 
-const findItemsEndingSoon = `-- name: FindItemsEndingSoon :many
-SELECT i.id, i.subscription_id, i.goodwill_id, i.created_at, i.started_at, i.ends_at, i.sent_final FROM items i
+const deleteOrphanedItemReminders = `-- name: DeleteOrphanedItemReminders :exec
+DELETE FROM item_reminders
+WHERE item_id NOT IN (SELECT id FROM items)
+  AND item_id NOT IN (SELECT id FROM watched_items)
+`
+
+func (q *Queries) DeleteOrphanedItemReminders(ctx context.Context) error {
+	_, err := q.db.ExecContext(ctx, deleteOrphanedItemReminders)
+	return err
+}
+
+const findDueReminders = `-- name: FindDueReminders :many
+SELECT i.id, i.subscription_id, i.goodwill_id, i.created_at, i.started_at, i.ends_at, i.current_price, i.num_bids, i.buy_now_price, i.checked_at, CAST(r.value AS INTEGER) AS minutes
+FROM items i
 JOIN subscriptions s ON i.subscription_id = s.id
-WHERE i.ends_at < datetime('now', '+' || s.notify_minutes || ' minutes') AND i.sent_final = FALSE
+JOIN json_each('[' || s.reminders || ']') r
+WHERE i.ends_at < datetime('now', '+' || r.value || ' minutes')
+  AND i.ends_at > CURRENT_TIMESTAMP
+  AND NOT EXISTS (SELECT 1 FROM item_reminders ir WHERE ir.item_id = i.id AND ir.minutes = r.value)
+  AND s.paused_at IS NULL
+  AND s.user_id NOT IN (SELECT user_id FROM user_settings WHERE vacation_at IS NOT NULL)
 LIMIT 100
 `
 
-func (q *Queries) FindItemsEndingSoon(ctx context.Context) ([]Item, error) {
-	rows, err := q.db.QueryContext(ctx, findItemsEndingSoon)
+type FindDueRemindersRow struct {
+	Item    Item
+	Minutes int64
+}
+
+func (q *Queries) FindDueReminders(ctx context.Context) ([]FindDueRemindersRow, error) {
+	rows, err := q.db.QueryContext(ctx, findDueReminders)
 	if err != nil {
 		return nil, err
 	}
 	defer rows.Close()
-	var items []Item
+	var items []FindDueRemindersRow
 	for rows.Next() {
-		var i Item
+		var i FindDueRemindersRow
 		if err := rows.Scan(
-			&i.ID,
-			&i.SubscriptionID,
-			&i.GoodwillID,
-			&i.CreatedAt,
-			&i.StartedAt,
-			&i.EndsAt,
-			&i.SentFinal,
+			&i.Item.ID,
+			&i.Item.SubscriptionID,
+			&i.Item.GoodwillID,
+			&i.Item.CreatedAt,
+			&i.Item.StartedAt,
+			&i.Item.EndsAt,
+			&i.Item.CurrentPrice,
+			&i.Item.NumBids,
+			&i.Item.BuyNowPrice,
+			&i.Item.CheckedAt,
+			&i.Minutes,
 		); err != nil {
 			return nil, err
 		}
@@ -114,43 +157,156 @@ func (q *Queries) FindItemsEndingSoon(ctx context.Context) ([]Item, error) {
 	return items, nil
 }
 
-const isItemTracked = `-- name: IsItemTracked :one
-SELECT EXISTS (
-  SELECT 1
-  FROM items
-  WHERE subscription_id = ? AND goodwill_id = ?
-) AS is_tracked
+const findItemsToRefresh = `-- name: FindItemsToRefresh :many
+SELECT i.id, i.subscription_id, i.goodwill_id, i.created_at, i.started_at, i.ends_at, i.current_price, i.num_bids, i.buy_now_price, i.checked_at, s.id, s.user_id, s.term, s.min_price, s.max_price, s.category_id, s.last_notified_at, s.category_level, s.paused_at, s.paused_until, s.exclude_words, s.require_words, s.filter_category, s.delivery, s.reminders, s.priority, s.poll_interval, s.next_poll_at
+FROM items i
+JOIN subscriptions s ON i.subscription_id = s.id
+JOIN alert_messages am ON am.user_id = s.user_id AND am.goodwill_id = i.goodwill_id
+WHERE (i.checked_at IS NULL OR i.checked_at < datetime('now', '-15 minutes'))
+  AND i.ends_at > CURRENT_TIMESTAMP
+  AND s.paused_at IS NULL
+  AND s.user_id NOT IN (SELECT user_id FROM user_settings WHERE vacation_at IS NOT NULL)
+ORDER BY i.checked_at, i.goodwill_id
+LIMIT 100
 `
 
-type IsItemTrackedParams struct {
-	SubscriptionID string
-	GoodwillID     int64
+type FindItemsToRefreshRow struct {
+	Item         Item
+	Subscription Subscription
 }
 
-func (q *Queries) IsItemTracked(ctx context.Context, arg IsItemTrackedParams) (int64, error) {
-	row := q.db.QueryRowContext(ctx, isItemTracked, arg.SubscriptionID, arg.GoodwillID)
-	var is_tracked int64
-	err := row.Scan(&is_tracked)
-	return is_tracked, err
+func (q *Queries) FindItemsToRefresh(ctx context.Context) ([]FindItemsToRefreshRow, error) {
+	rows, err := q.db.QueryContext(ctx, findItemsToRefresh)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []FindItemsToRefreshRow
+	for rows.Next() {
+		var i FindItemsToRefreshRow
+		if err := rows.Scan(
+			&i.Item.ID,
+			&i.Item.SubscriptionID,
+			&i.Item.GoodwillID,
+			&i.Item.CreatedAt,
+			&i.Item.StartedAt,
+			&i.Item.EndsAt,
+			&i.Item.CurrentPrice,
+			&i.Item.NumBids,
+			&i.Item.BuyNowPrice,
+			&i.Item.CheckedAt,
+			&i.Subscription.ID,
+			&i.Subscription.UserID,
+			&i.Subscription.Term,
+			&i.Subscription.MinPrice,
+			&i.Subscription.MaxPrice,
+			&i.Subscription.CategoryID,
+			&i.Subscription.LastNotifiedAt,
+			&i.Subscription.CategoryLevel,
+			&i.Subscription.PausedAt,
+			&i.Subscription.PausedUntil,
+			&i.Subscription.ExcludeWords,
+			&i.Subscription.RequireWords,
+			&i.Subscription.FilterCategory,
+			&i.Subscription.Delivery,
+			&i.Subscription.Reminders,
+			&i.Subscription.Priority,
+			&i.Subscription.PollInterval,
+			&i.Subscription.NextPollAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
 }
 
-const setItemSentFinal = `-- name: SetItemSentFinal :exec
-UPDATE items
-SET sent_final = TRUE
-WHERE id IN (/*SLICE:ids*/?)
+const findTrackedGoodwillIDs = `-- name: FindTrackedGoodwillIDs :many
+SELECT goodwill_id
+FROM items
+WHERE subscription_id = ? AND goodwill_id IN (/*SLICE:goodwill_ids*/?)
 `
 
-func (q *Queries) SetItemSentFinal(ctx context.Context, ids []string) error {
-	query := setItemSentFinal
+type FindTrackedGoodwillIDsParams struct {
+	SubscriptionID string
+	GoodwillIds    []int64
+}
+
+func (q *Queries) FindTrackedGoodwillIDs(ctx context.Context, arg FindTrackedGoodwillIDsParams) ([]int64, error) {
+	query := findTrackedGoodwillIDs
 	var queryParams []interface{}
-	if len(ids) > 0 {
-		for _, v := range ids {
+	queryParams = append(queryParams, arg.SubscriptionID)
+	if len(arg.GoodwillIds) > 0 {
+		for _, v := range arg.GoodwillIds {
 			queryParams = append(queryParams, v)
 		}
-		query = strings.Replace(query, "/*SLICE:ids*/?", strings.Repeat(",?", len(ids))[1:], 1)
+		query = strings.Replace(query, "/*SLICE:goodwill_ids*/?", strings.Repeat(",?", len(arg.GoodwillIds))[1:], 1)
 	} else {
-		query = strings.Replace(query, "/*SLICE:ids*/?", "NULL", 1)
+		query = strings.Replace(query, "/*SLICE:goodwill_ids*/?", "NULL", 1)
 	}
-	_, err := q.db.ExecContext(ctx, query, queryParams...)
+	rows, err := q.db.QueryContext(ctx, query, queryParams...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []int64
+	for rows.Next() {
+		var goodwill_id int64
+		if err := rows.Scan(&goodwill_id); err != nil {
+			return nil, err
+		}
+		items = append(items, goodwill_id)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const setItemChecked = `-- name: SetItemChecked :exec
+UPDATE items
+SET current_price = ?, num_bids = ?, buy_now_price = ?, checked_at = CURRENT_TIMESTAMP
+WHERE id = ?
+`
+
+type SetItemCheckedParams struct {
+	CurrentPrice float64
+	NumBids      int64
+	BuyNowPrice  float64
+	ID           string
+}
+
+func (q *Queries) SetItemChecked(ctx context.Context, arg SetItemCheckedParams) error {
+	_, err := q.db.ExecContext(ctx, setItemChecked,
+		arg.CurrentPrice,
+		arg.NumBids,
+		arg.BuyNowPrice,
+		arg.ID,
+	)
+	return err
+}
+
+const setItemReminderSent = `-- name: SetItemReminderSent :exec
+INSERT INTO item_reminders (item_id, minutes, sent_at)
+VALUES (?, ?, CURRENT_TIMESTAMP)
+ON CONFLICT (item_id, minutes) DO NOTHING
+`
+
+type SetItemReminderSentParams struct {
+	ItemID  string
+	Minutes int64
+}
+
+func (q *Queries) SetItemReminderSent(ctx context.Context, arg SetItemReminderSentParams) error {
+	_, err := q.db.ExecContext(ctx, setItemReminderSent, arg.ItemID, arg.Minutes)
 	return err
 }
@@ -8,6 +8,37 @@ import (
 	"time"
 )
 
+type AlertMessage struct {
+	UserID     string
+	GoodwillID int64
+	ChannelID  string
+	MessageID  string
+	CreatedAt  time.Time
+}
+
+type ItemReminder struct {
+	ItemID  string
+	Minutes int64
+	SentAt  time.Time
+}
+
+type ItemResultFailure struct {
+	GoodwillID    int64
+	Attempts      int64
+	LastError     string
+	Final         bool
+	NextAttemptAt time.Time
+}
+
+type ItemResult struct {
+	GoodwillID int64
+	Title      string
+	FinalPrice float64
+	NumBids    int64
+	EndedAt    time.Time
+	CreatedAt  time.Time
+}
+
 type Item struct {
 	ID             string
 	SubscriptionID string
@@ -15,7 +46,50 @@ type Item struct {
 	CreatedAt      time.Time
 	StartedAt      time.Time
 	EndsAt         time.Time
-	SentFinal      bool
+	CurrentPrice   float64
+	NumBids        int64
+	BuyNowPrice    float64
+	CheckedAt      *time.Time
+}
+
+type OutboxMessage struct {
+	ID             string
+	UserID         string
+	SubscriptionID string
+	Kind           string
+	Notification   string
+	Sink           string
+	Target         string
+	Status         string
+	Attempts       int64
+	LastError      string
+	NextAttemptAt  time.Time
+	CreatedAt      time.Time
+	SentAt         *time.Time
+}
+
+type QueuedItem struct {
+	ID             string
+	SubscriptionID string
+	GoodwillID     int64
+	Item           string
+	CreatedAt      time.Time
+}
+
+type Search struct {
+	ID             string
+	UserID         string
+	Term           string
+	MinPrice       *int64
+	MaxPrice       *int64
+	CategoryID     *int64
+	CategoryLevel  *int64
+	ExcludeWords   string
+	RequireWords   string
+	FilterCategory bool
+	Descending     bool
+	Results        *string
+	CreatedAt      time.Time
 }
 
 type Subscription struct {
@@ -26,5 +100,57 @@ type Subscription struct {
 	MaxPrice       *int64
 	CategoryID     *int64
 	LastNotifiedAt time.Time
-	NotifyMinutes  int64
+	CategoryLevel  *int64
+	PausedAt       *time.Time
+	PausedUntil    *time.Time
+	ExcludeWords   string
+	RequireWords   string
+	FilterCategory bool
+	Delivery       string
+	Reminders      string
+	Priority       bool
+	PollInterval   int64
+	NextPollAt     *time.Time
+}
+
+type UserSetting struct {
+	UserID                 string
+	VacationAt             *time.Time
+	VacationUntil          *time.Time
+	Email                  *string
+	PendingEmail           *string
+	EmailCode              *string
+	EmailCodeExpiresAt     *time.Time
+	EmailCodeAttempts      int64
+	Delivery               string
+	DigestHour             int64
+	Timezone               string
+	QuietStart             *int64
+	QuietEnd               *int64
+	EndingSoonInQuietHours bool
+	BidJump                int64
+	SoldReports            bool
+}
+
+type WatchedItem struct {
+	ID           string
+	UserID       string
+	GoodwillID   int64
+	Title        string
+	EndsAt       time.Time
+	Reminders    string
+	CreatedAt    time.Time
+	CurrentPrice float64
+	NumBids      int64
+	BuyNowPrice  float64
+	CheckedAt    *time.Time
+}
+
+type Webhook struct {
+	ID             string
+	UserID         string
+	SubscriptionID string
+	Url            string
+	Secret         string
+	CreatedAt      time.Time
 }
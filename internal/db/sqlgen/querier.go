@@ -9,18 +9,76 @@ import (
 )
 
 type Querier interface {
+	AddUserEmailCodeAttempt(ctx context.Context, userID string) (UserSetting, error)
+	ConfirmUserEmail(ctx context.Context, userID string) error
+	CreateAlertMessage(ctx context.Context, arg CreateAlertMessageParams) error
 	CreateItem(ctx context.Context, arg CreateItemParams) (Item, error)
+	CreateItemResult(ctx context.Context, arg CreateItemResultParams) (ItemResult, error)
+	CreateOutboxMessage(ctx context.Context, arg CreateOutboxMessageParams) error
+	CreateSearch(ctx context.Context, arg CreateSearchParams) (Search, error)
 	CreateSubscription(ctx context.Context, arg CreateSubscriptionParams) (Subscription, error)
+	DeleteExpiredAlertMessages(ctx context.Context) error
 	DeleteExpiredItems(ctx context.Context) error
+	DeleteExpiredOutboxMessages(ctx context.Context) error
+	DeleteExpiredSearches(ctx context.Context) error
+	DeleteExpiredWatchedItems(ctx context.Context) error
 	DeleteItemsInSubscriptions(ctx context.Context, ids []string) error
+	DeleteOrphanedItemReminders(ctx context.Context) error
+	DeleteOrphanedItemResultFailures(ctx context.Context) error
+	DeleteOutboxMessagesInSubscriptions(ctx context.Context, ids []string) error
+	DeleteQueuedItems(ctx context.Context, ids []string) error
+	DeleteQueuedItemsInSubscriptions(ctx context.Context, ids []string) error
 	DeleteUserSubscriptions(ctx context.Context, arg DeleteUserSubscriptionsParams) error
-	FindItemsEndingSoon(ctx context.Context) ([]Item, error)
+	DeleteUserWatchedItems(ctx context.Context, arg DeleteUserWatchedItemsParams) error
+	DeleteUserWebhooks(ctx context.Context, arg DeleteUserWebhooksParams) error
+	DeleteWebhooksInSubscriptions(ctx context.Context, ids []string) error
+	EndUserVacation(ctx context.Context, userID string) error
+	FindAlertMessage(ctx context.Context, arg FindAlertMessageParams) (AlertMessage, error)
+	FindDueOutboxMessages(ctx context.Context) ([]OutboxMessage, error)
+	FindDueReminders(ctx context.Context) ([]FindDueRemindersRow, error)
+	FindDueWatchedReminders(ctx context.Context) ([]FindDueWatchedRemindersRow, error)
+	FindEndedItemsWithoutResult(ctx context.Context) ([]FindEndedItemsWithoutResultRow, error)
+	FindExpiredVacations(ctx context.Context) ([]UserSetting, error)
+	FindItemsToRefresh(ctx context.Context) ([]FindItemsToRefreshRow, error)
+	FindQueuedItems(ctx context.Context, subscriptionID string) ([]QueuedItem, error)
+	FindSoldReportSubscriptions(ctx context.Context, goodwillID int64) ([]Subscription, error)
 	FindSubscription(ctx context.Context, id string) (Subscription, error)
+	FindSubscriptionWebhooks(ctx context.Context, arg FindSubscriptionWebhooksParams) ([]Webhook, error)
 	FindSubscriptionsToNotify(ctx context.Context) ([]Subscription, error)
+	FindSubscriptionsToResume(ctx context.Context) ([]Subscription, error)
+	FindSubscriptionsWithQueuedItems(ctx context.Context) ([]Subscription, error)
+	FindTrackedGoodwillIDs(ctx context.Context, arg FindTrackedGoodwillIDsParams) ([]int64, error)
+	FindUserSearch(ctx context.Context, arg FindUserSearchParams) (Search, error)
+	FindUserSettings(ctx context.Context, userID string) (UserSetting, error)
 	FindUserSubscriptions(ctx context.Context, userID string) ([]Subscription, error)
-	IsItemTracked(ctx context.Context, arg IsItemTrackedParams) (int64, error)
-	SetItemSentFinal(ctx context.Context, ids []string) error
+	FindUserWatchedItems(ctx context.Context, userID string) ([]WatchedItem, error)
+	FindUserWebhooks(ctx context.Context, userID string) ([]Webhook, error)
+	FindWatchedItemsToRefresh(ctx context.Context) ([]WatchedItem, error)
+	FindWebhook(ctx context.Context, id string) (Webhook, error)
+	PauseUserSubscriptions(ctx context.Context, arg PauseUserSubscriptionsParams) error
+	QueueItem(ctx context.Context, arg QueueItemParams) error
+	RemoveUserEmail(ctx context.Context, userID string) error
+	ResumeSubscription(ctx context.Context, id string) error
+	SetItemChecked(ctx context.Context, arg SetItemCheckedParams) error
+	SetItemReminderSent(ctx context.Context, arg SetItemReminderSentParams) error
+	SetItemResultFailed(ctx context.Context, arg SetItemResultFailedParams) error
+	SetOutboxMessageDead(ctx context.Context, arg SetOutboxMessageDeadParams) error
+	SetOutboxMessageFailed(ctx context.Context, arg SetOutboxMessageFailedParams) error
+	SetOutboxMessageSent(ctx context.Context, id string) error
+	SetSearchResults(ctx context.Context, arg SetSearchResultsParams) error
+	SetSubscriptionDelivery(ctx context.Context, arg SetSubscriptionDeliveryParams) error
 	SetSubscriptionLastNotifiedAt(ctx context.Context, id string) error
+	SetSubscriptionNextPoll(ctx context.Context, arg SetSubscriptionNextPollParams) error
+	SetSubscriptionPriority(ctx context.Context, arg SetSubscriptionPriorityParams) error
+	SetUserDelivery(ctx context.Context, arg SetUserDeliveryParams) error
+	SetUserPreferences(ctx context.Context, arg SetUserPreferencesParams) error
+	SetWatchedItemChecked(ctx context.Context, arg SetWatchedItemCheckedParams) error
+	StartUserEmailVerification(ctx context.Context, arg StartUserEmailVerificationParams) error
+	StartUserVacation(ctx context.Context, arg StartUserVacationParams) error
+	UpdateSubscription(ctx context.Context, arg UpdateSubscriptionParams) (Subscription, error)
+	UpdateSubscriptionFilters(ctx context.Context, arg UpdateSubscriptionFiltersParams) (Subscription, error)
+	UpsertWebhook(ctx context.Context, arg UpsertWebhookParams) (Webhook, error)
+	WatchItem(ctx context.Context, arg WatchItemParams) (WatchedItem, error)
 }
 
 var _ Querier = (*Queries)(nil)
@@ -8,21 +8,27 @@ package sqlgen
 import (
 	"context"
 	"strings"
+	"time"
 )
 
 const createSubscription = `-- name: CreateSubscription :one
-INSERT INTO subscriptions (id, user_id, term, last_notified_at, min_price, max_price, notify_minutes)
-VALUES (?, ?, ?, 0, ?, ?, ?)
-RETURNING id, user_id, term, min_price, max_price, category_id, last_notified_at, notify_minutes
+INSERT INTO subscriptions (id, user_id, term, last_notified_at, min_price, max_price, reminders, category_id, category_level, exclude_words, require_words, filter_category)
+VALUES (?, ?, ?, 0, ?, ?, ?, ?, ?, ?, ?, ?)
+RETURNING id, user_id, term, min_price, max_price, category_id, last_notified_at, category_level, paused_at, paused_until, exclude_words, require_words, filter_category, delivery, reminders, priority, poll_interval, next_poll_at
 `
 
 type CreateSubscriptionParams struct {
-	ID            string
-	UserID        string
-	Term          string
-	MinPrice      *int64
-	MaxPrice      *int64
-	NotifyMinutes int64
+	ID             string
+	UserID         string
+	Term           string
+	MinPrice       *int64
+	MaxPrice       *int64
+	Reminders      string
+	CategoryID     *int64
+	CategoryLevel  *int64
+	ExcludeWords   string
+	RequireWords   string
+	FilterCategory bool
 }
 
 func (q *Queries) CreateSubscription(ctx context.Context, arg CreateSubscriptionParams) (Subscription, error) {
@@ -32,7 +38,12 @@ func (q *Queries) CreateSubscription(ctx context.Context, arg CreateSubscription
 		arg.Term,
 		arg.MinPrice,
 		arg.MaxPrice,
-		arg.NotifyMinutes,
+		arg.Reminders,
+		arg.CategoryID,
+		arg.CategoryLevel,
+		arg.ExcludeWords,
+		arg.RequireWords,
+		arg.FilterCategory,
 	)
 	var i Subscription
 	err := row.Scan(
@@ -43,7 +54,17 @@ func (q *Queries) CreateSubscription(ctx context.Context, arg CreateSubscription
 		&i.MaxPrice,
 		&i.CategoryID,
 		&i.LastNotifiedAt,
-		&i.NotifyMinutes,
+		&i.CategoryLevel,
+		&i.PausedAt,
+		&i.PausedUntil,
+		&i.ExcludeWords,
+		&i.RequireWords,
+		&i.FilterCategory,
+		&i.Delivery,
+		&i.Reminders,
+		&i.Priority,
+		&i.PollInterval,
+		&i.NextPollAt,
 	)
 	return i, err
 }
@@ -75,7 +96,7 @@ func (q *Queries) DeleteUserSubscriptions(ctx context.Context, arg DeleteUserSub
 }
 
 const findSubscription = `-- name: FindSubscription :one
-SELECT id, user_id, term, min_price, max_price, category_id, last_notified_at, notify_minutes FROM subscriptions
+SELECT id, user_id, term, min_price, max_price, category_id, last_notified_at, category_level, paused_at, paused_until, exclude_words, require_words, filter_category, delivery, reminders, priority, poll_interval, next_poll_at FROM subscriptions
 WHERE id = ?
 `
 
@@ -90,14 +111,27 @@ func (q *Queries) FindSubscription(ctx context.Context, id string) (Subscription
 		&i.MaxPrice,
 		&i.CategoryID,
 		&i.LastNotifiedAt,
-		&i.NotifyMinutes,
+		&i.CategoryLevel,
+		&i.PausedAt,
+		&i.PausedUntil,
+		&i.ExcludeWords,
+		&i.RequireWords,
+		&i.FilterCategory,
+		&i.Delivery,
+		&i.Reminders,
+		&i.Priority,
+		&i.PollInterval,
+		&i.NextPollAt,
 	)
 	return i, err
 }
 
 const findSubscriptionsToNotify = `-- name: FindSubscriptionsToNotify :many
-SELECT id, user_id, term, min_price, max_price, category_id, last_notified_at, notify_minutes FROM subscriptions
-WHERE last_notified_at < datetime('now', '-5 minutes')
+SELECT id, user_id, term, min_price, max_price, category_id, last_notified_at, category_level, paused_at, paused_until, exclude_words, require_words, filter_category, delivery, reminders, priority, poll_interval, next_poll_at FROM subscriptions
+WHERE (next_poll_at IS NULL OR next_poll_at <= CURRENT_TIMESTAMP)
+  AND paused_at IS NULL
+  AND user_id NOT IN (SELECT user_id FROM user_settings WHERE vacation_at IS NOT NULL)
+ORDER BY next_poll_at
 LIMIT 100
 `
 
@@ -118,7 +152,65 @@ func (q *Queries) FindSubscriptionsToNotify(ctx context.Context) ([]Subscription
 			&i.MaxPrice,
 			&i.CategoryID,
 			&i.LastNotifiedAt,
-			&i.NotifyMinutes,
+			&i.CategoryLevel,
+			&i.PausedAt,
+			&i.PausedUntil,
+			&i.ExcludeWords,
+			&i.RequireWords,
+			&i.FilterCategory,
+			&i.Delivery,
+			&i.Reminders,
+			&i.Priority,
+			&i.PollInterval,
+			&i.NextPollAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const findSubscriptionsToResume = `-- name: FindSubscriptionsToResume :many
+SELECT id, user_id, term, min_price, max_price, category_id, last_notified_at, category_level, paused_at, paused_until, exclude_words, require_words, filter_category, delivery, reminders, priority, poll_interval, next_poll_at FROM subscriptions
+WHERE paused_until IS NOT NULL AND paused_until <= CURRENT_TIMESTAMP
+LIMIT 100
+`
+
+func (q *Queries) FindSubscriptionsToResume(ctx context.Context) ([]Subscription, error) {
+	rows, err := q.db.QueryContext(ctx, findSubscriptionsToResume)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Subscription
+	for rows.Next() {
+		var i Subscription
+		if err := rows.Scan(
+			&i.ID,
+			&i.UserID,
+			&i.Term,
+			&i.MinPrice,
+			&i.MaxPrice,
+			&i.CategoryID,
+			&i.LastNotifiedAt,
+			&i.CategoryLevel,
+			&i.PausedAt,
+			&i.PausedUntil,
+			&i.ExcludeWords,
+			&i.RequireWords,
+			&i.FilterCategory,
+			&i.Delivery,
+			&i.Reminders,
+			&i.Priority,
+			&i.PollInterval,
+			&i.NextPollAt,
 		); err != nil {
 			return nil, err
 		}
@@ -134,7 +226,7 @@ func (q *Queries) FindSubscriptionsToNotify(ctx context.Context) ([]Subscription
 }
 
 const findUserSubscriptions = `-- name: FindUserSubscriptions :many
-SELECT id, user_id, term, min_price, max_price, category_id, last_notified_at, notify_minutes FROM subscriptions
+SELECT id, user_id, term, min_price, max_price, category_id, last_notified_at, category_level, paused_at, paused_until, exclude_words, require_words, filter_category, delivery, reminders, priority, poll_interval, next_poll_at FROM subscriptions
 WHERE user_id = ?
 `
 
@@ -155,7 +247,17 @@ func (q *Queries) FindUserSubscriptions(ctx context.Context, userID string) ([]S
 			&i.MaxPrice,
 			&i.CategoryID,
 			&i.LastNotifiedAt,
-			&i.NotifyMinutes,
+			&i.CategoryLevel,
+			&i.PausedAt,
+			&i.PausedUntil,
+			&i.ExcludeWords,
+			&i.RequireWords,
+			&i.FilterCategory,
+			&i.Delivery,
+			&i.Reminders,
+			&i.Priority,
+			&i.PollInterval,
+			&i.NextPollAt,
 		); err != nil {
 			return nil, err
 		}
@@ -170,6 +272,63 @@ func (q *Queries) FindUserSubscriptions(ctx context.Context, userID string) ([]S
 	return items, nil
 }
 
+const pauseUserSubscriptions = `-- name: PauseUserSubscriptions :exec
+UPDATE subscriptions
+SET paused_at = CURRENT_TIMESTAMP, paused_until = ?
+WHERE user_id = ? AND id IN (/*SLICE:ids*/?)
+`
+
+type PauseUserSubscriptionsParams struct {
+	PausedUntil *time.Time
+	UserID      string
+	Ids         []string
+}
+
+func (q *Queries) PauseUserSubscriptions(ctx context.Context, arg PauseUserSubscriptionsParams) error {
+	query := pauseUserSubscriptions
+	var queryParams []interface{}
+	queryParams = append(queryParams, arg.PausedUntil)
+	queryParams = append(queryParams, arg.UserID)
+	if len(arg.Ids) > 0 {
+		for _, v := range arg.Ids {
+			queryParams = append(queryParams, v)
+		}
+		query = strings.Replace(query, "/*SLICE:ids*/?", strings.Repeat(",?", len(arg.Ids))[1:], 1)
+	} else {
+		query = strings.Replace(query, "/*SLICE:ids*/?", "NULL", 1)
+	}
+	_, err := q.db.ExecContext(ctx, query, queryParams...)
+	return err
+}
+
+const resumeSubscription = `-- name: ResumeSubscription :exec
+UPDATE subscriptions
+SET paused_at = NULL, paused_until = NULL
+WHERE id = ?
+`
+
+func (q *Queries) ResumeSubscription(ctx context.Context, id string) error {
+	_, err := q.db.ExecContext(ctx, resumeSubscription, id)
+	return err
+}
+
+const setSubscriptionDelivery = `-- name: SetSubscriptionDelivery :exec
+UPDATE subscriptions
+SET delivery = ?
+WHERE id = ? AND user_id = ?
+`
+
+type SetSubscriptionDeliveryParams struct {
+	Delivery string
+	ID       string
+	UserID   string
+}
+
+func (q *Queries) SetSubscriptionDelivery(ctx context.Context, arg SetSubscriptionDeliveryParams) error {
+	_, err := q.db.ExecContext(ctx, setSubscriptionDelivery, arg.Delivery, arg.ID, arg.UserID)
+	return err
+}
+
 const setSubscriptionLastNotifiedAt = `-- name: SetSubscriptionLastNotifiedAt :exec
 UPDATE subscriptions
 SET last_notified_at = CURRENT_TIMESTAMP
@@ -180,3 +339,139 @@ func (q *Queries) SetSubscriptionLastNotifiedAt(ctx context.Context, id string)
 	_, err := q.db.ExecContext(ctx, setSubscriptionLastNotifiedAt, id)
 	return err
 }
+
+const setSubscriptionNextPoll = `-- name: SetSubscriptionNextPoll :exec
+UPDATE subscriptions
+SET poll_interval = ?, next_poll_at = ?
+WHERE id = ?
+`
+
+type SetSubscriptionNextPollParams struct {
+	PollInterval int64
+	NextPollAt   *time.Time
+	ID           string
+}
+
+func (q *Queries) SetSubscriptionNextPoll(ctx context.Context, arg SetSubscriptionNextPollParams) error {
+	_, err := q.db.ExecContext(ctx, setSubscriptionNextPoll, arg.PollInterval, arg.NextPollAt, arg.ID)
+	return err
+}
+
+const setSubscriptionPriority = `-- name: SetSubscriptionPriority :exec
+UPDATE subscriptions
+SET priority = ?, poll_interval = ?, next_poll_at = NULL
+WHERE id = ? AND user_id = ?
+`
+
+type SetSubscriptionPriorityParams struct {
+	Priority     bool
+	PollInterval int64
+	ID           string
+	UserID       string
+}
+
+func (q *Queries) SetSubscriptionPriority(ctx context.Context, arg SetSubscriptionPriorityParams) error {
+	_, err := q.db.ExecContext(ctx, setSubscriptionPriority,
+		arg.Priority,
+		arg.PollInterval,
+		arg.ID,
+		arg.UserID,
+	)
+	return err
+}
+
+const updateSubscription = `-- name: UpdateSubscription :one
+UPDATE subscriptions
+SET term = ?, min_price = ?, max_price = ?, reminders = ?
+WHERE id = ? AND user_id = ?
+RETURNING id, user_id, term, min_price, max_price, category_id, last_notified_at, category_level, paused_at, paused_until, exclude_words, require_words, filter_category, delivery, reminders, priority, poll_interval, next_poll_at
+`
+
+type UpdateSubscriptionParams struct {
+	Term      string
+	MinPrice  *int64
+	MaxPrice  *int64
+	Reminders string
+	ID        string
+	UserID    string
+}
+
+func (q *Queries) UpdateSubscription(ctx context.Context, arg UpdateSubscriptionParams) (Subscription, error) {
+	row := q.db.QueryRowContext(ctx, updateSubscription,
+		arg.Term,
+		arg.MinPrice,
+		arg.MaxPrice,
+		arg.Reminders,
+		arg.ID,
+		arg.UserID,
+	)
+	var i Subscription
+	err := row.Scan(
+		&i.ID,
+		&i.UserID,
+		&i.Term,
+		&i.MinPrice,
+		&i.MaxPrice,
+		&i.CategoryID,
+		&i.LastNotifiedAt,
+		&i.CategoryLevel,
+		&i.PausedAt,
+		&i.PausedUntil,
+		&i.ExcludeWords,
+		&i.RequireWords,
+		&i.FilterCategory,
+		&i.Delivery,
+		&i.Reminders,
+		&i.Priority,
+		&i.PollInterval,
+		&i.NextPollAt,
+	)
+	return i, err
+}
+
+const updateSubscriptionFilters = `-- name: UpdateSubscriptionFilters :one
+UPDATE subscriptions
+SET exclude_words = ?, require_words = ?, filter_category = ?
+WHERE id = ? AND user_id = ?
+RETURNING id, user_id, term, min_price, max_price, category_id, last_notified_at, category_level, paused_at, paused_until, exclude_words, require_words, filter_category, delivery, reminders, priority, poll_interval, next_poll_at
+`
+
+type UpdateSubscriptionFiltersParams struct {
+	ExcludeWords   string
+	RequireWords   string
+	FilterCategory bool
+	ID             string
+	UserID         string
+}
+
+func (q *Queries) UpdateSubscriptionFilters(ctx context.Context, arg UpdateSubscriptionFiltersParams) (Subscription, error) {
+	row := q.db.QueryRowContext(ctx, updateSubscriptionFilters,
+		arg.ExcludeWords,
+		arg.RequireWords,
+		arg.FilterCategory,
+		arg.ID,
+		arg.UserID,
+	)
+	var i Subscription
+	err := row.Scan(
+		&i.ID,
+		&i.UserID,
+		&i.Term,
+		&i.MinPrice,
+		&i.MaxPrice,
+		&i.CategoryID,
+		&i.LastNotifiedAt,
+		&i.CategoryLevel,
+		&i.PausedAt,
+		&i.PausedUntil,
+		&i.ExcludeWords,
+		&i.RequireWords,
+		&i.FilterCategory,
+		&i.Delivery,
+		&i.Reminders,
+		&i.Priority,
+		&i.PollInterval,
+		&i.NextPollAt,
+	)
+	return i, err
+}
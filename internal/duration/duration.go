@@ -0,0 +1,79 @@
+package duration
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var units = map[byte]time.Duration{
+	'w': 7 * 24 * time.Hour,
+	'd': 24 * time.Hour,
+	'h': time.Hour,
+	'm': time.Minute,
+	's': time.Second,
+}
+
+// Parse parses durations like "3d", "1w2d" or "1h30m". Unlike time.ParseDuration, it supports days and weeks, and
+// the duration has to be positive.
+func Parse(s string) (time.Duration, error) {
+	s = strings.ToLower(strings.TrimSpace(s))
+	if s == "" {
+		return 0, fmt.Errorf("empty duration")
+	}
+
+	var total time.Duration
+	for s != "" {
+		i := 0
+		for i < len(s) && s[i] >= '0' && s[i] <= '9' {
+			i++
+		}
+
+		if i == 0 || i == len(s) {
+			return 0, fmt.Errorf("invalid duration: %q", s)
+		}
+
+		n, err := strconv.ParseInt(s[:i], 10, 64)
+		if err != nil {
+			return 0, err
+		}
+
+		unit, ok := units[s[i]]
+		if !ok {
+			return 0, fmt.Errorf("unknown unit %q in duration", s[i])
+		}
+
+		if n > int64(math.MaxInt64/unit) || total > math.MaxInt64-time.Duration(n)*unit {
+			return 0, fmt.Errorf("duration is too long")
+		}
+
+		total += time.Duration(n) * unit
+		s = s[i+1:]
+	}
+
+	if total <= 0 {
+		return 0, fmt.Errorf("duration must be longer than zero")
+	}
+
+	return total, nil
+}
+
+// Format formats a duration in the same compact form accepted by Parse, like "1d2h".
+func Format(d time.Duration) string {
+	if d <= 0 {
+		return "0m"
+	}
+
+	b := strings.Builder{}
+	for _, unit := range []byte{'d', 'h', 'm', 's'} {
+		if n := d / units[unit]; n > 0 {
+			b.WriteString(strconv.FormatInt(int64(n), 10))
+			b.WriteByte(unit)
+			d -= n * units[unit]
+		}
+	}
+
+	return b.String()
+}
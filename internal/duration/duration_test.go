@@ -0,0 +1,63 @@
+package duration
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    time.Duration
+		wantErr bool
+	}{
+		{in: "3d", want: 3 * 24 * time.Hour},
+		{in: "1w2d", want: 9 * 24 * time.Hour},
+		{in: "1h30m", want: 90 * time.Minute},
+		{in: " 2H ", want: 2 * time.Hour},
+		{in: "45s", want: 45 * time.Second},
+		{in: "", wantErr: true},
+		{in: "3", wantErr: true},
+		{in: "d", wantErr: true},
+		{in: "3y", wantErr: true},
+		{in: "-3d", wantErr: true},
+		{in: "0m", wantErr: true},
+		{in: "0d0h", wantErr: true},
+		{in: "99999999999w", wantErr: true},
+		{in: "15000w15000w", wantErr: true},
+		{in: "99999999999999999999s", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.in, func(t *testing.T) {
+			got, err := Parse(tt.in)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Parse(%q) error = %v, wantErr %v", tt.in, err, tt.wantErr)
+			}
+
+			if got != tt.want {
+				t.Errorf("Parse(%q) = %v, want %v", tt.in, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFormat(t *testing.T) {
+	tests := []struct {
+		in   time.Duration
+		want string
+	}{
+		{in: 0, want: "0m"},
+		{in: 90 * time.Minute, want: "1h30m"},
+		{in: 9*24*time.Hour + 2*time.Hour, want: "9d2h"},
+		{in: 45 * time.Second, want: "45s"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.want, func(t *testing.T) {
+			if got := Format(tt.in); got != tt.want {
+				t.Errorf("Format(%v) = %q, want %q", tt.in, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,85 @@
+package gw
+
+import (
+	"sync"
+	"time"
+)
+
+// breaker stops requests to ShopGoodwill after too many failures in a row. Once the cooldown passes, a single request
+// is let through to check if it's back up.
+type breaker struct {
+	threshold int
+	cooldown  time.Duration
+	now       func() time.Time
+
+	mu       sync.Mutex
+	failures int
+	openedAt time.Time
+	probing  bool
+}
+
+func newBreaker(threshold int, cooldown time.Duration) *breaker {
+	return &breaker{threshold: threshold, cooldown: cooldown, now: time.Now}
+}
+
+// Allow reports if a request can be made, and if it's the one request let through while half open. Only that probe
+// passes probe to Failure or Abort, so requests made before the breaker opened can't end the half open state.
+func (b *breaker) Allow() (ok, probe bool) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.openedAt.IsZero() {
+		return true, false
+	}
+
+	if b.now().Sub(b.openedAt) < b.cooldown || b.probing {
+		return false, false
+	}
+
+	b.probing = true
+	return true, true
+}
+
+// Open reports if requests are being stopped, without using up the request let through when half open.
+func (b *breaker) Open() bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	return !b.openedAt.IsZero() && (b.now().Sub(b.openedAt) < b.cooldown || b.probing)
+}
+
+func (b *breaker) Success() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.failures = 0
+	b.openedAt = time.Time{}
+	b.probing = false
+}
+
+// Failure records a failed request, opening the breaker once there are too many in a row or if the request let
+// through when half open failed.
+func (b *breaker) Failure(probe bool) (opened bool) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.failures++
+	if (probe && b.probing) || (b.openedAt.IsZero() && b.threshold > 0 && b.failures >= b.threshold) {
+		b.openedAt = b.now()
+		b.probing = false
+		return true
+	}
+
+	return false
+}
+
+// Abort lets another request through when half open, if the probe was canceled before it could succeed or fail.
+// Other requests are ignored.
+func (b *breaker) Abort(probe bool) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if probe {
+		b.probing = false
+	}
+}
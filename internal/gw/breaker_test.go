@@ -0,0 +1,42 @@
+package gw
+
+import (
+	"testing"
+	"time"
+)
+
+func TestBreakerProbe(t *testing.T) {
+	clock := newFakeClock()
+	b := newBreaker(1, time.Minute)
+	b.now = clock.Now
+
+	// a request made before the breaker opened
+	_, early := b.Allow()
+
+	if !b.Failure(false) {
+		t.Fatal("Failure() didn't open the breaker")
+	}
+
+	clock.Advance(time.Minute)
+
+	ok, probe := b.Allow()
+	if !ok || !probe {
+		t.Fatalf("Allow() = %v, %v after the cooldown, want true, true", ok, probe)
+	}
+
+	// only the probe can let another request through
+	b.Abort(early)
+	if ok, _ := b.Allow(); ok {
+		t.Error("Allow() = true after another request aborted, want false")
+	}
+
+	b.Failure(early)
+	if ok, _ := b.Allow(); ok {
+		t.Error("Allow() = true after another request failed, want false")
+	}
+
+	b.Abort(probe)
+	if ok, probe := b.Allow(); !ok || !probe {
+		t.Errorf("Allow() = %v, %v after the probe aborted, want true, true", ok, probe)
+	}
+}
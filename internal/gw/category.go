@@ -0,0 +1,150 @@
+package gw
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+)
+
+// CategoryCacheTTL is how long the category tree is cached before it is fetched again.
+const CategoryCacheTTL = 24 * time.Hour
+
+type Category struct {
+	ID       int64  `json:"categoryId"`
+	Name     string `json:"categoryName"`
+	ParentID int64  `json:"parentId"`
+
+	// Level is the depth of the category in the tree, starting at 1 for top level categories.
+	Level int `json:"-"`
+	// FullName is the path of the category, like "Electronics > Cameras > Lenses".
+	FullName string      `json:"-"`
+	Children []*Category `json:"-"`
+}
+
+type CategoryTree struct {
+	Roots []*Category
+	byID  map[int64]*Category
+}
+
+func NewCategoryTree(categories []Category) *CategoryTree {
+	tree := &CategoryTree{
+		Roots: make([]*Category, 0),
+		byID:  make(map[int64]*Category, len(categories)),
+	}
+
+	for i := range categories {
+		tree.byID[categories[i].ID] = &categories[i]
+	}
+
+	for i := range categories {
+		category := &categories[i]
+		parent, ok := tree.byID[category.ParentID]
+		if !ok || parent == category {
+			tree.Roots = append(tree.Roots, category)
+			continue
+		}
+		parent.Children = append(parent.Children, category)
+	}
+
+	var walk func(categories []*Category, level int, prefix string)
+	walk = func(categories []*Category, level int, prefix string) {
+		sort.Slice(categories, func(i, j int) bool {
+			return categories[i].Name < categories[j].Name
+		})
+
+		for _, category := range categories {
+			category.Level = level
+			category.FullName = prefix + category.Name
+			walk(category.Children, level+1, category.FullName+" > ")
+		}
+	}
+	walk(tree.Roots, 1, "")
+
+	return tree
+}
+
+func (t *CategoryTree) Find(id int64) (*Category, bool) {
+	if t == nil {
+		return nil, false
+	}
+	category, ok := t.byID[id]
+	return category, ok
+}
+
+// Search finds categories whose full name contains the query (case-insensitive), shallowest first.
+func (t *CategoryTree) Search(query string, limit int) []*Category {
+	query = strings.ToLower(strings.TrimSpace(query))
+
+	matches := make([]*Category, 0)
+	var walk func(categories []*Category)
+	walk = func(categories []*Category) {
+		for _, category := range categories {
+			if strings.Contains(strings.ToLower(category.FullName), query) {
+				matches = append(matches, category)
+			}
+			walk(category.Children)
+		}
+	}
+	walk(t.Roots)
+
+	sort.SliceStable(matches, func(i, j int) bool {
+		return matches[i].Level < matches[j].Level
+	})
+
+	if len(matches) > limit {
+		matches = matches[:limit]
+	}
+
+	return matches
+}
+
+// Categories returns the ShopGoodwill category tree, fetching it at most once per CategoryCacheTTL. The cached tree
+// is returned without waiting on any fetch, and a stale one is returned if fetching it again fails.
+func (c *Client) Categories(ctx context.Context) (*CategoryTree, error) {
+	c.categoriesMu.Lock()
+	cached, fetchedAt := c.categories, c.categoriesFetchedAt
+	c.categoriesMu.Unlock()
+
+	if cached != nil && time.Since(fetchedAt) < CategoryCacheTTL {
+		return cached, nil
+	}
+
+	tree, err := c.fetchCategories(ctx)
+	if err != nil {
+		// categories rarely change, so a stale tree is better than none
+		if cached != nil {
+			return cached, nil
+		}
+		return nil, err
+	}
+
+	c.categoriesMu.Lock()
+	c.categories = tree
+	c.categoriesFetchedAt = time.Now()
+	c.categoriesMu.Unlock()
+
+	return tree, nil
+}
+
+func (c *Client) fetchCategories(ctx context.Context) (*CategoryTree, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, c.baseURL+"/api/Master/GetMasterCategoryList", nil)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := c.do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	var categories []Category
+	if err := json.NewDecoder(resp.Body).Decode(&categories); err != nil {
+		return nil, err
+	}
+
+	return NewCategoryTree(categories), nil
+}
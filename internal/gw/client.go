@@ -4,29 +4,140 @@ import (
 	"bytes"
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
+	"iter"
 	"log/slog"
+	"math/rand/v2"
 	"net/http"
+	"slices"
+	"sync"
+	"time"
 )
 
 const (
 	DefaultBaseURL = "https://buyerapi.shopgoodwill.com"
+
+	DefaultTimeout          = 15 * time.Second
+	DefaultRetries          = 3
+	DefaultInitialBackoff   = 1 * time.Second
+	DefaultMaxBackoff       = 30 * time.Second
+	DefaultBreakerThreshold = 5
+	DefaultBreakerCooldown  = 1 * time.Minute
+	DefaultRateLimit        = 0.5
+	DefaultRateBurst        = 5
 )
 
 type Client struct {
 	*http.Client
 	baseURL string
+
+	// timeout is for each attempt of a request, not all of them.
+	timeout        time.Duration
+	retries        int
+	initialBackoff time.Duration
+	maxBackoff     time.Duration
+	breaker        *breaker
+	limiter        *limiter
+
+	categoriesMu        sync.Mutex
+	categories          *CategoryTree
+	categoriesFetchedAt time.Time
 }
 
-func New() *Client {
-	return &Client{
-		Client:  http.DefaultClient,
-		baseURL: DefaultBaseURL,
+type Option func(*Client)
+
+func WithBaseURL(baseURL string) Option {
+	return func(c *Client) {
+		c.baseURL = baseURL
 	}
 }
 
+func WithTimeout(timeout time.Duration) Option {
+	return func(c *Client) {
+		c.timeout = timeout
+	}
+}
+
+// WithRetries sets how many times a request is retried after a network error, rate limit or server error.
+func WithRetries(retries int) Option {
+	return func(c *Client) {
+		c.retries = retries
+	}
+}
+
+func WithBackoff(initial, max time.Duration) Option {
+	return func(c *Client) {
+		c.initialBackoff = initial
+		c.maxBackoff = max
+	}
+}
+
+// WithBreaker sets how many requests in a row can fail before requests are stopped, and for how long.
+func WithBreaker(threshold int, cooldown time.Duration) Option {
+	return func(c *Client) {
+		c.breaker = newBreaker(threshold, cooldown)
+	}
+}
+
+// WithRateLimit sets how many requests per second are made to ShopGoodwill, with bursts of up to burst requests. A
+// rate of zero doesn't limit requests at all.
+func WithRateLimit(rate float64, burst int) Option {
+	return func(c *Client) {
+		c.limiter = newLimiter(rate, burst)
+	}
+}
+
+func New(opts ...Option) *Client {
+	c := &Client{
+		Client:         &http.Client{},
+		baseURL:        DefaultBaseURL,
+		timeout:        DefaultTimeout,
+		retries:        DefaultRetries,
+		initialBackoff: DefaultInitialBackoff,
+		maxBackoff:     DefaultMaxBackoff,
+		breaker:        newBreaker(DefaultBreakerThreshold, DefaultBreakerCooldown),
+		limiter:        newLimiter(DefaultRateLimit, DefaultRateBurst),
+	}
+
+	for _, opt := range opts {
+		opt(c)
+	}
+
+	return c
+}
+
+// Available reports if requests are being made, which they aren't for a while after too many failed. Loops should
+// skip their work instead of failing every request.
+func (c *Client) Available() bool {
+	return !c.breaker.Open()
+}
+
+// WaitStats returns how long requests waited for the rate limiter since the last call, by priority.
+func (c *Client) WaitStats() map[Priority]WaitStats {
+	return c.limiter.Stats()
+}
+
+// SearchResults is a single page of search results.
+type SearchResults struct {
+	Items []Item
+	// Total is the number of items across all pages.
+	Total int
+}
+
+// Search returns the first page of items for the term, see Pages to search more than one.
 func (c *Client) Search(ctx context.Context, term string, opts ...SearchOption) ([]Item, error) {
+	res, err := c.SearchPage(ctx, term, opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	return res.Items, nil
+}
+
+// SearchPage returns a page of items for the term, the first page unless WithPage is given.
+func (c *Client) SearchPage(ctx context.Context, term string, opts ...SearchOption) (*SearchResults, error) {
 	query, err := NewSearchQuery(term, opts...)
 	if err != nil {
 		return nil, err
@@ -39,20 +150,16 @@ func (c *Client) Search(ctx context.Context, term string, opts ...SearchOption)
 
 	req.Header.Set("Content-Type", "application/json")
 
-	resp, err := c.Do(req)
+	resp, err := c.do(req)
 	if err != nil {
 		return nil, err
 	}
 	defer resp.Body.Close()
 
-	if resp.StatusCode != http.StatusOK {
-		logRequestError(ctx, req, resp)
-		return nil, fmt.Errorf("unexpected status code: %d", resp.StatusCode)
-	}
-
 	type response struct {
 		SearchResults struct {
-			Items []Item `json:"items"`
+			Items     []Item `json:"items"`
+			ItemCount int    `json:"itemCount"`
 		} `json:"searchResults"`
 	}
 
@@ -61,7 +168,34 @@ func (c *Client) Search(ctx context.Context, term string, opts ...SearchOption)
 		return nil, err
 	}
 
-	return res.SearchResults.Items, nil
+	return &SearchResults{
+		Items: res.SearchResults.Items,
+		Total: res.SearchResults.ItemCount,
+	}, nil
+}
+
+// Pages walks the pages of items for the term, starting at the first, until every item was seen or maxPages were
+// searched. Callers can stop early, like once they reach items they've already seen.
+func (c *Client) Pages(ctx context.Context, term string, maxPages int, opts ...SearchOption) iter.Seq2[[]Item, error] {
+	return func(yield func([]Item, error) bool) {
+		seen := 0
+		for page := 1; page <= max(maxPages, 1); page++ {
+			res, err := c.SearchPage(ctx, term, append(slices.Clip(opts), WithPage(page))...)
+			if err != nil {
+				yield(nil, err)
+				return
+			}
+
+			if !yield(res.Items, nil) {
+				return
+			}
+
+			seen += len(res.Items)
+			if len(res.Items) == 0 || seen >= res.Total {
+				return
+			}
+		}
+	}
 }
 
 func (c *Client) FindItem(ctx context.Context, id int64) (*Item, error) {
@@ -70,17 +204,12 @@ func (c *Client) FindItem(ctx context.Context, id int64) (*Item, error) {
 		return nil, err
 	}
 
-	resp, err := c.Do(req)
+	resp, err := c.do(req)
 	if err != nil {
 		return nil, err
 	}
 	defer resp.Body.Close()
 
-	if resp.StatusCode != http.StatusOK {
-		logRequestError(ctx, req, resp)
-		return nil, fmt.Errorf("unexpected status code: %d", resp.StatusCode)
-	}
-
 	var item Item
 	if err := json.NewDecoder(resp.Body).Decode(&item); err != nil {
 		return nil, err
@@ -89,12 +218,133 @@ func (c *Client) FindItem(ctx context.Context, id int64) (*Item, error) {
 	return &item, nil
 }
 
-func logRequestError(ctx context.Context, req *http.Request, resp *http.Response) {
-	body, _ := io.ReadAll(resp.Body)
+// do sends the request, retrying with jittered exponential backoff on network errors, rate limits and server errors.
+// Every attempt waits for the rate limiter, in the lane of the request's context. Any status other than 200 is
+// returned as a *StatusError.
+func (c *Client) do(req *http.Request) (*http.Response, error) {
+	ctx := req.Context()
+
+	ok, probe := c.breaker.Allow()
+	if !ok {
+		return nil, ErrCircuitOpen
+	}
+
+	backoff := c.initialBackoff
+	for attempt := 1; ; attempt++ {
+		if err := c.limiter.Wait(ctx); err != nil {
+			c.breaker.Abort(probe)
+			return nil, err
+		}
+
+		resp, err := c.attempt(req)
+		if err == nil {
+			c.breaker.Success()
+			return resp, nil
+		}
+
+		if ctx.Err() != nil {
+			c.breaker.Abort(probe)
+			return nil, err
+		}
+
+		var statusErr *StatusError
+		if errors.As(err, &statusErr) {
+			logRequestError(ctx, statusErr)
+
+			// the request was wrong, ShopGoodwill itself is fine
+			if !statusErr.Retryable() {
+				c.breaker.Success()
+				return nil, err
+			}
+		}
+
+		if attempt > c.retries {
+			if c.breaker.Failure(probe) {
+				slog.WarnContext(ctx, "too many failed requests, pausing requests to ShopGoodwill", "cooldown", c.breaker.cooldown)
+			}
+			return nil, err
+		}
+
+		wait := jitter(backoff)
+		if statusErr != nil && statusErr.RetryAfter > 0 {
+			wait = statusErr.RetryAfter
+		}
+		wait = min(wait, c.maxBackoff)
+
+		slog.WarnContext(ctx, "request to ShopGoodwill failed, retrying", "error", err, "attempt", attempt, "backoff", wait)
+
+		select {
+		case <-time.After(wait):
+			backoff = min(backoff*2, c.maxBackoff)
+		case <-ctx.Done():
+			c.breaker.Abort(probe)
+			return nil, ctx.Err()
+		}
+	}
+}
+
+// attempt sends the request once, with the client's timeout.
+func (c *Client) attempt(req *http.Request) (*http.Response, error) {
+	ctx, cancel := context.WithTimeout(req.Context(), c.timeout)
+
+	r := req.Clone(ctx)
+	if req.GetBody != nil {
+		body, err := req.GetBody()
+		if err != nil {
+			cancel()
+			return nil, err
+		}
+		r.Body = body
+	}
+
+	resp, err := c.Client.Do(r)
+	if err != nil {
+		cancel()
+		return nil, err
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		defer cancel()
+		defer resp.Body.Close()
+
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 4096))
+		return nil, &StatusError{
+			Method:     req.Method,
+			URL:        req.URL.String(),
+			StatusCode: resp.StatusCode,
+			Body:       string(body),
+			RetryAfter: parseRetryAfter(resp.Header.Get("Retry-After"), time.Now()),
+		}
+	}
+
+	// the timeout has to last until the body is read
+	resp.Body = &cancelOnClose{resp.Body, cancel}
+	return resp, nil
+}
+
+type cancelOnClose struct {
+	io.ReadCloser
+	cancel context.CancelFunc
+}
+
+func (b *cancelOnClose) Close() error {
+	defer b.cancel()
+	return b.ReadCloser.Close()
+}
+
+// jitter returns a random duration between half of d and d, so retries from many requests are spread out.
+func jitter(d time.Duration) time.Duration {
+	if d <= 1 {
+		return d
+	}
+	return d/2 + rand.N(d/2)
+}
+
+func logRequestError(ctx context.Context, err *StatusError) {
 	slog.ErrorContext(ctx, "unexpected status code",
-		"body", string(body),
-		"code", resp.StatusCode,
-		"url", req.URL.String(),
-		"method", req.Method,
+		"body", err.Body,
+		"code", err.StatusCode,
+		"url", err.URL,
+		"method", err.Method,
 	)
 }
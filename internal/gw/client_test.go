@@ -0,0 +1,267 @@
+package gw
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// fakeClock is a clock that only moves when it's told to.
+type fakeClock struct {
+	mu  sync.Mutex
+	now time.Time
+}
+
+func newFakeClock() *fakeClock {
+	return &fakeClock{now: time.Now()}
+}
+
+func (c *fakeClock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.now
+}
+
+func (c *fakeClock) Advance(d time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.now = c.now.Add(d)
+}
+
+// testServer responds to each request with the next of statuses, repeating the last one. A 200 is an item.
+func testServer(t *testing.T, statuses ...int) (*httptest.Server, *atomic.Int32) {
+	t.Helper()
+
+	var requests atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n := int(requests.Add(1))
+		status := statuses[min(n, len(statuses))-1]
+
+		if status != http.StatusOK {
+			w.WriteHeader(status)
+			return
+		}
+
+		fmt.Fprint(w, `{"itemId": 1, "title": "brass lamp"}`)
+	}))
+	t.Cleanup(srv.Close)
+
+	return srv, &requests
+}
+
+func testClient(srv *httptest.Server, opts ...Option) *Client {
+	return New(append([]Option{
+		WithBaseURL(srv.URL),
+		WithBackoff(time.Millisecond, time.Millisecond),
+		WithRateLimit(0, 0),
+	}, opts...)...)
+}
+
+func TestClientRetries(t *testing.T) {
+	tests := []struct {
+		name         string
+		statuses     []int
+		wantErr      error
+		wantStatus   int
+		wantRequests int32
+	}{
+		{name: "ok", statuses: []int{200}, wantRequests: 1},
+		{name: "server error then ok", statuses: []int{503, 200}, wantRequests: 2},
+		{name: "rate limited then ok", statuses: []int{429, 502, 200}, wantRequests: 3},
+		{name: "server error", statuses: []int{500}, wantStatus: 500, wantRequests: 3},
+		{name: "bad request", statuses: []int{400, 200}, wantStatus: 400, wantRequests: 1},
+		{name: "not found", statuses: []int{404, 200}, wantErr: ErrNotFound, wantStatus: 404, wantRequests: 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv, requests := testServer(t, tt.statuses...)
+			c := testClient(srv, WithRetries(2))
+
+			item, err := c.FindItem(context.Background(), 1)
+			if tt.wantStatus == 0 {
+				if err != nil {
+					t.Fatalf("FindItem() error = %v", err)
+				}
+
+				if item.Title != "brass lamp" {
+					t.Errorf("FindItem() title = %q, want %q", item.Title, "brass lamp")
+				}
+			} else {
+				var statusErr *StatusError
+				if !errors.As(err, &statusErr) || statusErr.StatusCode != tt.wantStatus {
+					t.Fatalf("FindItem() error = %v, want status %d", err, tt.wantStatus)
+				}
+
+				if tt.wantErr != nil && !errors.Is(err, tt.wantErr) {
+					t.Errorf("FindItem() error = %v, want %v", err, tt.wantErr)
+				}
+			}
+
+			if got := requests.Load(); got != tt.wantRequests {
+				t.Errorf("got %d requests, want %d", got, tt.wantRequests)
+			}
+		})
+	}
+}
+
+func TestClientRetryAfter(t *testing.T) {
+	var requests atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if requests.Add(1) == 1 {
+			w.Header().Set("Retry-After", "1")
+			w.WriteHeader(http.StatusTooManyRequests)
+			return
+		}
+
+		fmt.Fprint(w, `{"itemId": 1}`)
+	}))
+	defer srv.Close()
+
+	// the backoff alone would retry right away
+	c := New(WithBaseURL(srv.URL), WithBackoff(time.Millisecond, time.Minute), WithRateLimit(0, 0))
+
+	start := time.Now()
+	if _, err := c.FindItem(context.Background(), 1); err != nil {
+		t.Fatalf("FindItem() error = %v", err)
+	}
+
+	if elapsed := time.Since(start); elapsed < time.Second {
+		t.Errorf("retried after %v, want at least 1s", elapsed)
+	}
+
+	if got := requests.Load(); got != 2 {
+		t.Errorf("got %d requests, want 2", got)
+	}
+}
+
+func TestClientBreaker(t *testing.T) {
+	ctx := context.Background()
+	clock := newFakeClock()
+
+	var requests, status atomic.Int32
+	status.Store(http.StatusServiceUnavailable)
+	received := make(chan struct{})
+	release := make(chan struct{})
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		if r.URL.Query().Has("hold") {
+			received <- struct{}{}
+			<-release
+		}
+
+		w.WriteHeader(int(status.Load()))
+		fmt.Fprint(w, `{"itemId": 1}`)
+	}))
+	defer srv.Close()
+
+	c := testClient(srv, WithRetries(0), WithBreaker(2, time.Minute))
+	c.breaker.now = clock.Now
+
+	find := func(hold bool) error {
+		url := srv.URL + "/api/ItemDetail/GetItemDetailModelByItemId/1"
+		if hold {
+			url += "?hold"
+		}
+
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		resp, err := c.do(req)
+		if err != nil {
+			return err
+		}
+		return resp.Body.Close()
+	}
+
+	// it opens after two failed requests in a row
+	for range 2 {
+		if err := find(false); errors.Is(err, ErrCircuitOpen) {
+			t.Fatalf("find() error = %v before the threshold", err)
+		}
+	}
+
+	if c.Available() {
+		t.Error("Available() = true after the threshold, want false")
+	}
+
+	if err := find(false); !errors.Is(err, ErrCircuitOpen) {
+		t.Fatalf("find() error = %v while open, want ErrCircuitOpen", err)
+	}
+
+	if got := requests.Load(); got != 2 {
+		t.Errorf("got %d requests, want 2 while open", got)
+	}
+
+	// after the cooldown, a single request is let through to check if it's back up
+	clock.Advance(time.Minute)
+	status.Store(http.StatusOK)
+
+	if !c.Available() {
+		t.Error("Available() = false after the cooldown, want true")
+	}
+
+	probe := make(chan error, 1)
+	go func() {
+		probe <- find(true)
+	}()
+	<-received
+
+	if c.Available() {
+		t.Error("Available() = true while probing, want false")
+	}
+
+	if err := find(false); !errors.Is(err, ErrCircuitOpen) {
+		t.Errorf("find() error = %v while probing, want ErrCircuitOpen", err)
+	}
+
+	close(release)
+	if err := <-probe; err != nil {
+		t.Fatalf("probe error = %v", err)
+	}
+
+	// the probe succeeded, so it's closed again
+	if err := find(false); err != nil {
+		t.Errorf("find() error = %v after the probe, want nil", err)
+	}
+
+	if got := requests.Load(); got != 4 {
+		t.Errorf("got %d requests, want 4", got)
+	}
+}
+
+func TestClientBreakerFailedProbe(t *testing.T) {
+	clock := newFakeClock()
+	srv, requests := testServer(t, http.StatusBadGateway)
+
+	c := testClient(srv, WithRetries(0), WithBreaker(1, time.Minute))
+	c.breaker.now = clock.Now
+
+	if _, err := c.FindItem(context.Background(), 1); errors.Is(err, ErrCircuitOpen) {
+		t.Fatalf("FindItem() error = %v before the threshold", err)
+	}
+
+	clock.Advance(time.Minute)
+
+	if _, err := c.FindItem(context.Background(), 1); errors.Is(err, ErrCircuitOpen) {
+		t.Fatalf("FindItem() error = %v for the probe", err)
+	}
+
+	// the probe failed, so it waits for another cooldown
+	if _, err := c.FindItem(context.Background(), 1); !errors.Is(err, ErrCircuitOpen) {
+		t.Errorf("FindItem() error = %v after a failed probe, want ErrCircuitOpen", err)
+	}
+
+	if got := requests.Load(); got != 2 {
+		t.Errorf("got %d requests, want 2", got)
+	}
+}
@@ -0,0 +1,70 @@
+package gw
+
+import (
+	"math"
+	"slices"
+)
+
+// PriceStats summarizes what closed auctions sold for.
+type PriceStats struct {
+	Count  int
+	Min    float64
+	Median float64
+	P90    float64
+	Max    float64
+}
+
+// NewPriceStats summarizes the final prices of closed auctions. Items without any bids didn't sell, so they're left
+// out.
+func NewPriceStats(items []Item) PriceStats {
+	prices := make([]float64, 0, len(items))
+	for _, item := range items {
+		if item.NumBids > 0 {
+			prices = append(prices, item.CurrentPrice)
+		}
+	}
+
+	if len(prices) == 0 {
+		return PriceStats{}
+	}
+
+	slices.Sort(prices)
+
+	return PriceStats{
+		Count:  len(prices),
+		Min:    prices[0],
+		Median: median(prices),
+		P90:    percentile(prices, 90),
+		Max:    prices[len(prices)-1],
+	}
+}
+
+// Sold returns the items that sold, most recently ended first.
+func Sold(items []Item) []Item {
+	sold := make([]Item, 0, len(items))
+	for _, item := range items {
+		if item.NumBids > 0 {
+			sold = append(sold, item)
+		}
+	}
+
+	slices.SortFunc(sold, func(a, b Item) int {
+		return b.EndTime.Compare(a.EndTime)
+	})
+
+	return sold
+}
+
+func median(sorted []float64) float64 {
+	mid := len(sorted) / 2
+	if len(sorted)%2 == 0 {
+		return (sorted[mid-1] + sorted[mid]) / 2
+	}
+	return sorted[mid]
+}
+
+// percentile uses the nearest rank, so it's always one of the prices.
+func percentile(sorted []float64, p float64) float64 {
+	rank := int(math.Ceil(p / 100 * float64(len(sorted))))
+	return sorted[max(rank, 1)-1]
+}
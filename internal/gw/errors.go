@@ -0,0 +1,56 @@
+package gw
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+var (
+	// ErrCircuitOpen is returned without making a request while ShopGoodwill looks down, see Client.Available.
+	ErrCircuitOpen = errors.New("circuit breaker is open, ShopGoodwill looks to be down")
+	ErrNotFound    = errors.New("not found")
+)
+
+// StatusError is returned when ShopGoodwill responds with an unexpected status code.
+type StatusError struct {
+	Method     string
+	URL        string
+	StatusCode int
+	Body       string
+	// RetryAfter is how long ShopGoodwill asked to wait before trying again, if it did.
+	RetryAfter time.Duration
+}
+
+func (e *StatusError) Error() string {
+	return fmt.Sprintf("unexpected status code %d from %s %s", e.StatusCode, e.Method, e.URL)
+}
+
+// Is makes a 404 match ErrNotFound.
+func (e *StatusError) Is(target error) bool {
+	return target == ErrNotFound && e.StatusCode == http.StatusNotFound
+}
+
+// Retryable reports if the same request might succeed later.
+func (e *StatusError) Retryable() bool {
+	return e.StatusCode == http.StatusTooManyRequests || e.StatusCode >= 500
+}
+
+// parseRetryAfter parses a Retry-After header, which is either seconds or an HTTP date.
+func parseRetryAfter(value string, now time.Time) time.Duration {
+	if value == "" {
+		return 0
+	}
+
+	if seconds, err := strconv.Atoi(value); err == nil && seconds > 0 {
+		return time.Duration(seconds) * time.Second
+	}
+
+	if t, err := http.ParseTime(value); err == nil && t.After(now) {
+		return t.Sub(now)
+	}
+
+	return 0
+}
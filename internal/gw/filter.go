@@ -0,0 +1,103 @@
+package gw
+
+import (
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/robherley/gw-bot/internal/db/sqlgen"
+)
+
+// Filter matches items locally, since ShopGoodwill's search has no way to exclude words.
+type Filter struct {
+	Exclude []string
+	Require []string
+	// MatchCategory also matches words against the full category name of an item, not just the title.
+	MatchCategory bool
+}
+
+func FilterFromSubscription(sub sqlgen.Subscription) Filter {
+	return Filter{
+		Exclude:       ParseWords(sub.ExcludeWords),
+		Require:       ParseWords(sub.RequireWords),
+		MatchCategory: sub.FilterCategory,
+	}
+}
+
+func (f Filter) IsZero() bool {
+	return len(f.Exclude) == 0 && len(f.Require) == 0
+}
+
+func (f Filter) Match(item Item) bool {
+	haystack := strings.ToLower(item.Title)
+	if f.MatchCategory {
+		haystack += " " + strings.ToLower(item.CategoryFullName)
+	}
+
+	for _, word := range f.Exclude {
+		if ContainsWord(haystack, word) {
+			return false
+		}
+	}
+
+	for _, word := range f.Require {
+		if !ContainsWord(haystack, word) {
+			return false
+		}
+	}
+
+	return true
+}
+
+func (f Filter) Apply(items []Item) []Item {
+	if f.IsZero() {
+		return items
+	}
+
+	filtered := make([]Item, 0, len(items))
+	for _, item := range items {
+		if f.Match(item) {
+			filtered = append(filtered, item)
+		}
+	}
+	return filtered
+}
+
+// ParseWords splits a comma separated list of words or phrases, like "cable, lens cap".
+func ParseWords(s string) []string {
+	words := make([]string, 0)
+	for _, word := range strings.Split(s, ",") {
+		word = strings.ToLower(strings.Join(strings.Fields(word), " "))
+		if word != "" {
+			words = append(words, word)
+		}
+	}
+	return words
+}
+
+func JoinWords(words []string) string {
+	return strings.Join(words, ", ")
+}
+
+// ContainsWord reports if word is in s at the start of a word, so "cable" matches "cables" but "case" won't match "showcase".
+func ContainsWord(s, word string) bool {
+	for offset := 0; offset <= len(s); {
+		i := strings.Index(s[offset:], word)
+		if i < 0 {
+			return false
+		}
+		i += offset
+
+		if i == 0 {
+			return true
+		}
+
+		prev, _ := utf8.DecodeLastRuneInString(s[:i])
+		if !unicode.IsLetter(prev) && !unicode.IsDigit(prev) {
+			return true
+		}
+
+		offset = i + 1
+	}
+	return false
+}
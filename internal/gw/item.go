@@ -2,8 +2,11 @@ package gw
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
 	"log/slog"
+	"net/url"
+	"strconv"
 	"strings"
 	"time"
 
@@ -27,7 +30,45 @@ type Item struct {
 }
 
 func (i *Item) URL() string {
-	return fmt.Sprintf("https://www.shopgoodwill.com/item/%d", i.ItemID)
+	return ItemURL(i.ItemID)
+}
+
+func ItemURL(id int64) string {
+	return fmt.Sprintf("https://www.shopgoodwill.com/item/%d", id)
+}
+
+// ParseItemID parses the ID out of an item URL like "https://shopgoodwill.com/item/123", or a bare ID.
+func ParseItemID(s string) (int64, error) {
+	s = strings.TrimSpace(s)
+	if id, err := strconv.ParseInt(s, 10, 64); err == nil && id > 0 {
+		return id, nil
+	}
+
+	if !strings.Contains(s, "://") {
+		s = "https://" + s
+	}
+
+	u, err := url.Parse(s)
+	if err != nil {
+		return 0, err
+	}
+
+	host := strings.TrimPrefix(strings.ToLower(u.Hostname()), "www.")
+	if host != "shopgoodwill.com" {
+		return 0, fmt.Errorf("not a shopgoodwill.com URL: %q", u.Hostname())
+	}
+
+	parts := strings.Split(strings.Trim(u.Path, "/"), "/")
+	if len(parts) != 2 || parts[0] != "item" {
+		return 0, errors.New("not an item URL")
+	}
+
+	id, err := strconv.ParseInt(parts[1], 10, 64)
+	if err != nil || id <= 0 {
+		return 0, fmt.Errorf("invalid item ID: %q", parts[1])
+	}
+
+	return id, nil
 }
 
 func (i *Item) HasAuction() bool {
@@ -140,11 +181,22 @@ func (i *Item) NewCreateItemParams(sub sqlgen.Subscription) sqlgen.CreateItemPar
 		SubscriptionID: sub.ID,
 		StartedAt:      i.StartTime,
 		EndsAt:         i.EndTime,
+		CurrentPrice:   i.CurrentPrice,
+		NumBids:        i.NumBids,
+		BuyNowPrice:    i.BuyNowPrice,
 	}
 }
 
+// TimeZone is the timezone of the times in ShopGoodwill's API responses.
+const TimeZone = "America/Los_Angeles"
+
 func inferTime(raw string) time.Time {
-	loc, err := time.LoadLocation("America/Los_Angeles")
+	// items that were already decoded and encoded again, like queued items, have the zone in their times
+	if ts, err := time.Parse(time.RFC3339Nano, raw); err == nil {
+		return ts.UTC()
+	}
+
+	loc, err := time.LoadLocation(TimeZone)
 	if err != nil {
 		slog.Error("failed to load location", "err", err)
 		return time.Time{}
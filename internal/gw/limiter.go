@@ -0,0 +1,183 @@
+package gw
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// Priority is the lane a request waits in for the rate limiter, requests in a higher lane always go first.
+type Priority int
+
+const (
+	// PriorityBackground is for polling in the looper, the default.
+	PriorityBackground Priority = iota
+	// PriorityInteractive is for commands someone is waiting on.
+	PriorityInteractive
+
+	numPriorities = int(PriorityInteractive) + 1
+)
+
+func (p Priority) String() string {
+	switch p {
+	case PriorityInteractive:
+		return "interactive"
+	default:
+		return "background"
+	}
+}
+
+type priorityKey struct{}
+
+// WithPriority returns a context whose requests wait in the given lane of the rate limiter.
+func WithPriority(ctx context.Context, p Priority) context.Context {
+	return context.WithValue(ctx, priorityKey{}, p)
+}
+
+func priorityFrom(ctx context.Context) Priority {
+	if p, ok := ctx.Value(priorityKey{}).(Priority); ok && p >= 0 && int(p) < numPriorities {
+		return p
+	}
+	return PriorityBackground
+}
+
+// WaitStats is how long requests waited for the rate limiter.
+type WaitStats struct {
+	Requests int
+	Total    time.Duration
+	Max      time.Duration
+}
+
+func (s WaitStats) Average() time.Duration {
+	if s.Requests == 0 {
+		return 0
+	}
+	return s.Total / time.Duration(s.Requests)
+}
+
+// limiter is a token bucket shared by every request of a client. Tokens are added at rate per second up to burst,
+// and each request takes one. While requests are waiting in a higher lane, lower lanes can't take any.
+type limiter struct {
+	rate  float64
+	burst float64
+	now   func() time.Time
+
+	mu      sync.Mutex
+	tokens  float64
+	last    time.Time
+	waiting [numPriorities]int
+	// changed is closed and replaced whenever a token is taken or a request stops waiting, to wake up the rest.
+	changed chan struct{}
+	stats   [numPriorities]WaitStats
+}
+
+// newLimiter returns a limiter allowing rate requests per second, or nil if rate isn't positive, which never waits.
+func newLimiter(rate float64, burst int) *limiter {
+	if rate <= 0 {
+		return nil
+	}
+
+	return &limiter{
+		rate:    rate,
+		burst:   float64(max(burst, 1)),
+		now:     time.Now,
+		tokens:  float64(max(burst, 1)),
+		last:    time.Now(),
+		changed: make(chan struct{}),
+	}
+}
+
+// Wait blocks until a token is taken in the lane of the context, or the context is done.
+func (l *limiter) Wait(ctx context.Context) error {
+	if l == nil {
+		return nil
+	}
+
+	p := priorityFrom(ctx)
+	start := l.now()
+
+	l.mu.Lock()
+	l.waiting[p]++
+	l.mu.Unlock()
+
+	for {
+		l.mu.Lock()
+		now := l.now()
+		l.refill(now)
+
+		if l.tokens >= 1 && !l.ahead(p) {
+			l.tokens--
+			l.done(p)
+			l.record(p, now.Sub(start))
+			l.mu.Unlock()
+			return nil
+		}
+
+		// with requests ahead, wait for them to take their tokens instead
+		wait := time.Duration((1 - l.tokens) / l.rate * float64(time.Second))
+		if l.ahead(p) {
+			wait = max(wait, time.Second)
+		}
+		changed := l.changed
+		l.mu.Unlock()
+
+		timer := time.NewTimer(wait)
+		select {
+		case <-timer.C:
+		case <-changed:
+			timer.Stop()
+		case <-ctx.Done():
+			timer.Stop()
+			l.mu.Lock()
+			l.done(p)
+			l.mu.Unlock()
+			return ctx.Err()
+		}
+	}
+}
+
+// Stats returns how long requests in each lane waited since the last call.
+func (l *limiter) Stats() map[Priority]WaitStats {
+	stats := make(map[Priority]WaitStats, numPriorities)
+	if l == nil {
+		return stats
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	for p := range l.stats {
+		stats[Priority(p)] = l.stats[p]
+		l.stats[p] = WaitStats{}
+	}
+
+	return stats
+}
+
+func (l *limiter) refill(now time.Time) {
+	l.tokens = min(l.burst, l.tokens+now.Sub(l.last).Seconds()*l.rate)
+	l.last = now
+}
+
+// ahead reports if any requests are waiting in a higher lane than p.
+func (l *limiter) ahead(p Priority) bool {
+	for q := int(p) + 1; q < numPriorities; q++ {
+		if l.waiting[q] > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+func (l *limiter) done(p Priority) {
+	l.waiting[p]--
+	close(l.changed)
+	l.changed = make(chan struct{})
+}
+
+func (l *limiter) record(p Priority, wait time.Duration) {
+	stats := &l.stats[p]
+	stats.Requests++
+	stats.Total += wait
+	stats.Max = max(stats.Max, wait)
+}
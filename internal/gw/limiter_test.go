@@ -0,0 +1,76 @@
+package gw
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestLimiterPriority(t *testing.T) {
+	clock := newFakeClock()
+	l := newLimiter(100, 1)
+	l.now = clock.Now
+	l.last = clock.Now()
+
+	background := context.Background()
+	interactive := WithPriority(background, PriorityInteractive)
+
+	// take the only token, the clock doesn't move until told to so no more are added
+	if err := l.Wait(background); err != nil {
+		t.Fatal(err)
+	}
+
+	served := make(chan Priority)
+	wait := func(ctx context.Context) {
+		if err := l.Wait(ctx); err != nil {
+			t.Error(err)
+		}
+		served <- priorityFrom(ctx)
+	}
+
+	for range 3 {
+		go wait(background)
+	}
+	waitFor(t, l, PriorityBackground, 3)
+
+	go wait(interactive)
+	waitFor(t, l, PriorityInteractive, 1)
+
+	// the interactive request was queued last, but takes the next token
+	want := []Priority{PriorityInteractive, PriorityBackground, PriorityBackground, PriorityBackground}
+	for i, p := range want {
+		clock.Advance(10 * time.Millisecond)
+
+		select {
+		case got := <-served:
+			if got != p {
+				t.Fatalf("request %d served %s, want %s", i, got, p)
+			}
+		case <-time.After(5 * time.Second):
+			t.Fatalf("request %d wasn't served", i)
+		}
+	}
+
+	if got := l.Stats()[PriorityInteractive].Requests; got != 1 {
+		t.Errorf("got %d interactive requests in stats, want 1", got)
+	}
+}
+
+// waitFor waits until n requests are waiting in the lane of p.
+func waitFor(t *testing.T, l *limiter, p Priority, n int) {
+	t.Helper()
+
+	deadline := time.Now().Add(5 * time.Second)
+	for time.Now().Before(deadline) {
+		l.mu.Lock()
+		waiting := l.waiting[p]
+		l.mu.Unlock()
+
+		if waiting == n {
+			return
+		}
+		time.Sleep(time.Millisecond)
+	}
+
+	t.Fatalf("%d requests never waited in the %s lane", n, p)
+}
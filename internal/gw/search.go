@@ -28,12 +28,43 @@ func WithDescending(descending bool) SearchOption {
 	}
 }
 
+// WithPage sets the page of results to return, starting at 1.
+func WithPage(page int) SearchOption {
+	return func(q map[string]any) {
+		q["page"] = strconv.Itoa(page)
+	}
+}
+
 func WithPageSize(size int) SearchOption {
 	return func(q map[string]any) {
 		q["pageSize"] = strconv.Itoa(size)
 	}
 }
 
+// WithClosedAuctions searches auctions that ended in the last daysBack days instead of open listings.
+func WithClosedAuctions(daysBack int) SearchOption {
+	return func(q map[string]any) {
+		q["searchClosedAuctions"] = "true"
+		q["closedAuctionDaysBack"] = strconv.Itoa(daysBack)
+	}
+}
+
+// WithClosedAuctionEndingDate sets the last day of a closed auction search, which is today by default.
+func WithClosedAuctionEndingDate(date time.Time) SearchOption {
+	return func(q map[string]any) {
+		q["closedAuctionEndingDate"] = date.Format("1/2/2006")
+	}
+}
+
+func WithCategory(id int64, level int64) SearchOption {
+	return func(q map[string]any) {
+		q["selectedCategoryIds"] = strconv.FormatInt(id, 10)
+		q["categoryId"] = id
+		q["categoryLevel"] = level
+		q["categoryLevelNo"] = strconv.FormatInt(level, 10)
+	}
+}
+
 func SearchOptionsFromSubscription(sub sqlgen.Subscription) []SearchOption {
 	opts := make([]SearchOption, 0)
 	if sub.MinPrice != nil {
@@ -44,6 +75,14 @@ func SearchOptionsFromSubscription(sub sqlgen.Subscription) []SearchOption {
 		opts = append(opts, WithMaxPrice(*sub.MaxPrice))
 	}
 
+	if sub.CategoryID != nil {
+		level := int64(1)
+		if sub.CategoryLevel != nil {
+			level = *sub.CategoryLevel
+		}
+		opts = append(opts, WithCategory(*sub.CategoryID, level))
+	}
+
 	return opts
 }
 
@@ -2,29 +2,73 @@ package looper
 
 import (
 	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
 	"log/slog"
+	"sync"
+	"sync/atomic"
 	"time"
 
-	"github.com/robherley/gw-bot/internal/bot"
 	"github.com/robherley/gw-bot/internal/db"
 	"github.com/robherley/gw-bot/internal/db/sqlgen"
 	"github.com/robherley/gw-bot/internal/gw"
+	"github.com/robherley/gw-bot/internal/notify"
+	"github.com/robherley/gw-bot/internal/tracker"
 )
 
 const (
-	TickNotifyNew    = 5 * time.Minute
+	TickNotifyNew    = 1 * time.Minute
 	TickNotifyEnding = 1 * time.Minute
 	TickCleanup      = 1 * time.Hour
+	TickResume       = 1 * time.Minute
+	TickDigest       = 1 * time.Minute
+	TickRefresh      = 5 * time.Minute
+	TickResults      = 10 * time.Minute
+	TickWaitStats    = 15 * time.Minute
+
+	// MaxResultAttempts is how many times the result of an ended item is looked up before it's given up on.
+	MaxResultAttempts     = 5
+	ResultsInitialBackoff = 30 * time.Minute
+	ResultsMaxBackoff     = 12 * time.Hour
+
+	// DeadlineNotifyNew is how long a tick has to poll subscriptions, the rest are polled next tick. Ticks run one
+	// after another, so it ends before the next one is due.
+	DeadlineNotifyNew = TickNotifyNew - 5*time.Second
 )
 
+// Client looks up items on ShopGoodwill, it's a *gw.Client outside of tests.
+type Client interface {
+	FindItem(ctx context.Context, id int64) (*gw.Item, error)
+	Available() bool
+	WaitStats() map[gw.Priority]gw.WaitStats
+}
+
 type Looper struct {
-	db  db.DB
-	bot *bot.Bot
-	gw  *gw.Client
+	db      db.DB
+	gw      Client
+	tracker *tracker.Tracker
+	sinks   []notify.Sink
+	// workers is how many searches for new items run at once.
+	workers   int
+	intervals PollIntervals
+
+	// wake sends the outbox before the next tick.
+	wake chan struct{}
 }
 
-func New(db db.DB, bot *bot.Bot, gw *gw.Client) *Looper {
-	return &Looper{db, bot, gw}
+// New creates a looper that sends notifications to all of the sinks through the outbox.
+func New(db db.DB, gw Client, tracker *tracker.Tracker, workers int, intervals PollIntervals, sinks ...notify.Sink) *Looper {
+	return &Looper{
+		db:        db,
+		gw:        gw,
+		tracker:   tracker,
+		sinks:     sinks,
+		workers:   max(workers, 1),
+		intervals: intervals,
+		wake:      make(chan struct{}, 1),
+	}
 }
 
 func (l *Looper) NotifyNewItems(ctx context.Context) {
@@ -37,63 +81,532 @@ func (l *Looper) NotifyNewItems(ctx context.Context) {
 	for {
 		select {
 		case <-ticker.C:
-			subscriptions, err := l.db.FindSubscriptionsToNotify(ctx)
+			if !l.available(log) {
+				continue
+			}
+
+			l.pollNewItems(ctx, log)
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// pollNewItems searches for new items of the due subscriptions with a pool of workers. Subscriptions that weren't
+// started by the deadline are left for the next tick.
+func (l *Looper) pollNewItems(ctx context.Context, log *slog.Logger) {
+	start := time.Now()
+
+	// searches stop at the deadline, items that were already found are still tracked and notified
+	tickCtx, cancel := context.WithTimeout(ctx, DeadlineNotifyNew)
+	defer cancel()
+
+	subscriptions, err := l.db.FindSubscriptionsToNotify(ctx)
+	if err != nil {
+		log.Error("failed to find subscriptions to notify", "error", err)
+		return
+	}
+
+	// subscriptions searching for the same thing share their searches
+	groups := l.tracker.Group(tickCtx, subscriptions, gw.WithDescending(true))
+	defer func() {
+		for _, group := range groups {
+			group.Close()
+		}
+	}()
+
+	var polled atomic.Int64
+	work := make(chan *tracker.Group)
+	wg := sync.WaitGroup{}
+
+	for range min(l.workers, len(groups)) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			// a group's subscriptions are polled one after another, they share its pages
+			for group := range work {
+				for _, sub := range group.Subscriptions {
+					if tickCtx.Err() != nil || !l.available(log) {
+						break
+					}
+
+					log := log.With("subscription_id", sub.ID, "user_id", sub.UserID)
+					found, err := l.notifyNewItems(ctx, log, group, sub)
+					if err != nil {
+						log.Error("failed to notify new items", "error", err)
+					}
+
+					l.setNextPoll(ctx, log, sub, found, err)
+					polled.Add(1)
+				}
+			}
+		}()
+	}
+
+send:
+	for _, group := range groups {
+		select {
+		case work <- group:
+		case <-tickCtx.Done():
+			break send
+		}
+	}
+	close(work)
+	wg.Wait()
+
+	backlog := len(subscriptions) - int(polled.Load())
+	log = log.With(
+		"duration", time.Since(start),
+		"subscriptions", len(subscriptions),
+		"searches", len(groups),
+		"polled", polled.Load(),
+		"backlog", backlog,
+	)
+
+	if backlog > 0 {
+		log.Warn("finished polling before all subscriptions were polled")
+	} else {
+		log.Info("finished polling")
+	}
+}
+
+// setNextPoll schedules when the subscription is searched again, sooner if it found new items.
+func (l *Looper) setNextPoll(ctx context.Context, log *slog.Logger, sub sqlgen.Subscription, found int, err error) {
+	interval := l.intervals.Next(sub, found)
+	if err != nil {
+		// failed searches don't say anything about how busy the subscription is
+		interval = l.intervals.Clamp(time.Duration(sub.PollInterval) * time.Second)
+	}
+
+	next := time.Now().Add(interval).UTC()
+	if err := l.db.SetSubscriptionNextPoll(ctx, sqlgen.SetSubscriptionNextPollParams{
+		PollInterval: int64(interval / time.Second),
+		NextPollAt:   &next,
+		ID:           sub.ID,
+	}); err != nil {
+		log.Error("failed to set next poll", "error", err)
+	}
+}
+
+// notifyNewItems tracks and notifies the items listed for the subscription since it was last searched, returning how
+// many were found.
+func (l *Looper) notifyNewItems(ctx context.Context, log *slog.Logger, group *tracker.Group, sub sqlgen.Subscription) (int, error) {
+	newItems, err := l.findNewItems(ctx, log, group, sub)
+	if err != nil {
+		return 0, err
+	}
+
+	if len(newItems) == 0 {
+		log.Info("no new items found")
+		return 0, nil
+	}
+
+	log.Info("new items found", "count", len(newItems))
+
+	// items that only match once they're closer to ending are tracked now, and sent by the ending soon reminders
+	term := tracker.Query(sub)
+	matched := make([]gw.Item, 0, len(newItems))
+	for _, item := range newItems {
+		if term.Match(item) {
+			matched = append(matched, item)
+		}
+	}
+
+	settings, err := l.findUserSettings(ctx, sub.UserID)
+	if err != nil {
+		log.Error("failed to find user settings", "error", err)
+	}
+
+	// items are only tracked along with their notification, otherwise they're found again next time
+	if err := l.db.WithTx(ctx, func(q sqlgen.Querier) error {
+		if err := tracker.CreateItems(ctx, q, sub, newItems); err != nil {
+			return err
+		}
+
+		if len(matched) == 0 {
+			return nil
+		}
+
+		if notify.ScheduleFor(sub, settings).Holds(time.Now()) {
+			// sent later by SendDigests
+			for _, item := range matched {
+				if err := queueItem(ctx, q, sub, item); err != nil {
+					return err
+				}
+			}
+		} else if err := l.enqueue(ctx, q, notify.Notification{
+			Kind:         notify.KindNewItems,
+			Subscription: sub,
+			Items:        matched,
+			Location:     notify.LocationFor(settings),
+		}); err != nil {
+			return err
+		}
+
+		return q.SetSubscriptionLastNotifiedAt(ctx, sub.ID)
+	}); err != nil {
+		// nothing was tracked, so the same items are found again next time
+		return 0, fmt.Errorf("track new items: %w", err)
+	}
+
+	l.wakeDispatcher()
+
+	return len(newItems), nil
+}
+
+// findNewItems walks the newest items of the subscription until it reaches items that are already tracked, so
+// nothing is missed when more than a page of items were listed since the last search.
+func (l *Looper) findNewItems(ctx context.Context, log *slog.Logger, group *tracker.Group, sub sqlgen.Subscription) ([]gw.Item, error) {
+	newItems := make([]gw.Item, 0)
+	seen := map[int64]bool{}
+
+	for items, err := range group.Pages(sub) {
+		if err != nil {
+			// anything found on earlier pages is still new, the rest is found next time
+			if len(newItems) > 0 {
+				log.Warn("failed to search for more items", "error", err)
+				break
+			}
+			return nil, err
+		}
+
+		// items can move to the next page while paging
+		unseen := make([]gw.Item, 0, len(items))
+		for _, item := range items {
+			if !seen[item.ItemID] {
+				seen[item.ItemID] = true
+				unseen = append(unseen, item)
+			}
+		}
+
+		tracked, err := l.tracker.Tracked(ctx, sub.ID, unseen)
+		if err != nil {
+			return nil, err
+		}
+
+		reachedTracked := false
+		for _, item := range unseen {
+			if tracked[item.ItemID] {
+				reachedTracked = true
+			} else {
+				newItems = append(newItems, item)
+			}
+		}
+
+		if reachedTracked {
+			break
+		}
+	}
+
+	return newItems, nil
+}
+
+func (l *Looper) NotifyEndingSoonItems(ctx context.Context) {
+	ticker := time.NewTicker(TickNotifyEnding)
+	defer ticker.Stop()
+
+	log := slog.With("component", "looper.notify.ending")
+	log.Info("starting loop", "tick", TickNotifyEnding)
+
+	for {
+		select {
+		case <-ticker.C:
+			if !l.available(log) {
+				continue
+			}
+
+			l.notifyEndingSoonItems(ctx, log)
+			l.notifyWatchedItems(ctx, log)
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// notifyEndingSoonItems sends the due reminders of tracked items, one notification per subscription.
+func (l *Looper) notifyEndingSoonItems(ctx context.Context, log *slog.Logger) {
+	reminders, err := l.db.FindDueReminders(ctx)
+	if err != nil {
+		log.Error("failed to find due reminders", "error", err)
+		return
+	}
+
+	sub2reminders := map[string][]sqlgen.FindDueRemindersRow{}
+	for _, reminder := range reminders {
+		subID := reminder.Item.SubscriptionID
+		sub2reminders[subID] = append(sub2reminders[subID], reminder)
+	}
+
+	for subID, reminders := range sub2reminders {
+		if !l.available(log) {
+			break
+		}
+
+		log := log.With("subscription_id", subID)
+
+		sub, err := l.db.FindSubscription(ctx, subID)
+		if err != nil {
+			log.Error("failed to find subscription", "error", err)
+			continue
+		}
+
+		// an item can be due for more than one reminder if it was missed, but it's only sent once
+		items := make([]sqlgen.Item, 0, len(reminders))
+		seen := map[string]bool{}
+		for _, reminder := range reminders {
+			if !seen[reminder.Item.ID] {
+				seen[reminder.Item.ID] = true
+				items = append(items, reminder.Item)
+			}
+		}
+
+		log.Info("found ending soon items", "count", len(items))
+
+		settings, err := l.findUserSettings(ctx, sub.UserID)
+		if err != nil {
+			log.Error("failed to find user settings", "error", err)
+		}
+
+		// the items will have ended by the time quiet hours are over, so they're skipped instead of held
+		if settings != nil && !settings.EndingSoonInQuietHours && notify.QuietHoursFor(settings).Contains(time.Now()) {
+			log.Info("skipping ending soon items during quiet hours")
+			l.setRemindersSent(ctx, log, reminders)
+			continue
+		}
+
+		term := tracker.Query(sub)
+		gwItems := make([]gw.Item, 0, len(items))
+		found := map[string]bool{}
+		for _, item := range items {
+			gwItem, err := l.gw.FindItem(ctx, item.GoodwillID)
 			if err != nil {
-				log.Error("failed to find subscriptions to notify", "error", err)
+				log.Error("failed to find item", "error", err, "goodwill_id", item.GoodwillID)
 				continue
 			}
 
-			for _, sub := range subscriptions {
-				time.Sleep(2 * time.Second)
+			found[item.ID] = true
+
+			// items still too far from ending for `ends:` aren't sent, their reminders are used up
+			if term.HasEnds() && !term.Match(*gwItem) {
+				continue
+			}
+
+			gwItems = append(gwItems, *gwItem)
+		}
 
-				opts := gw.SearchOptionsFromSubscription(sub)
-				opts = append(opts, gw.WithDescending(true))
+		// reminders of items that couldn't be found are tried again on the next tick
+		if len(found) == 0 {
+			continue
+		}
 
+		// reminders are only marked sent along with their notification
+		if err := l.db.WithTx(ctx, func(q sqlgen.Querier) error {
+			if len(gwItems) > 0 {
+				if err := l.enqueue(ctx, q, notify.Notification{
+					Kind:         notify.KindEndingSoon,
+					Subscription: sub,
+					Items:        gwItems,
+					Location:     notify.LocationFor(settings),
+				}); err != nil {
+					return err
+				}
+			}
+
+			for _, reminder := range reminders {
+				if !found[reminder.Item.ID] {
+					continue
+				}
+
+				if err := q.SetItemReminderSent(ctx, sqlgen.SetItemReminderSentParams{
+					ItemID:  reminder.Item.ID,
+					Minutes: reminder.Minutes,
+				}); err != nil {
+					return err
+				}
+			}
+
+			return nil
+		}); err != nil {
+			log.Error("failed to notify ending soon items", "error", err)
+			continue
+		}
+
+		l.wakeDispatcher()
+	}
+}
+
+// notifyWatchedItems sends reminders for items watched with /watch, which aren't part of any subscription.
+func (l *Looper) notifyWatchedItems(ctx context.Context, log *slog.Logger) {
+	reminders, err := l.db.FindDueWatchedReminders(ctx)
+	if err != nil {
+		log.Error("failed to find due watched reminders", "error", err)
+		return
+	}
+
+	user2reminders := map[string][]sqlgen.FindDueWatchedRemindersRow{}
+	for _, reminder := range reminders {
+		userID := reminder.WatchedItem.UserID
+		user2reminders[userID] = append(user2reminders[userID], reminder)
+	}
+
+	for userID, reminders := range user2reminders {
+		if !l.available(log) {
+			break
+		}
+
+		log := log.With("user_id", userID)
+
+		watched := make([]sqlgen.WatchedItem, 0, len(reminders))
+		seen := map[string]bool{}
+		for _, reminder := range reminders {
+			if !seen[reminder.WatchedItem.ID] {
+				seen[reminder.WatchedItem.ID] = true
+				watched = append(watched, reminder.WatchedItem)
+			}
+		}
+
+		log.Info("found ending soon watched items", "count", len(watched))
+
+		settings, err := l.findUserSettings(ctx, userID)
+		if err != nil {
+			log.Error("failed to find user settings", "error", err)
+		}
+
+		if settings != nil && !settings.EndingSoonInQuietHours && notify.QuietHoursFor(settings).Contains(time.Now()) {
+			log.Info("skipping ending soon watched items during quiet hours")
+			for _, reminder := range reminders {
+				l.setReminderSent(ctx, log, reminder.WatchedItem.ID, reminder.Minutes)
+			}
+			continue
+		}
+
+		gwItems := make([]gw.Item, 0, len(watched))
+		found := map[string]bool{}
+		for _, w := range watched {
+			gwItem, err := l.gw.FindItem(ctx, w.GoodwillID)
+			if err != nil {
+				log.Error("failed to find item", "error", err, "goodwill_id", w.GoodwillID)
+				continue
+			}
+
+			gwItems = append(gwItems, *gwItem)
+			found[w.ID] = true
+		}
+
+		// reminders of items that couldn't be found are tried again on the next tick
+		if len(gwItems) == 0 {
+			continue
+		}
+
+		if err := l.db.WithTx(ctx, func(q sqlgen.Querier) error {
+			if err := l.enqueue(ctx, q, notify.Notification{
+				Kind:         notify.KindWatched,
+				Subscription: sqlgen.Subscription{UserID: userID},
+				Items:        gwItems,
+				Location:     notify.LocationFor(settings),
+			}); err != nil {
+				return err
+			}
+
+			for _, reminder := range reminders {
+				if !found[reminder.WatchedItem.ID] {
+					continue
+				}
+
+				if err := q.SetItemReminderSent(ctx, sqlgen.SetItemReminderSentParams{
+					ItemID:  reminder.WatchedItem.ID,
+					Minutes: reminder.Minutes,
+				}); err != nil {
+					return err
+				}
+			}
+
+			return nil
+		}); err != nil {
+			log.Error("failed to notify watched items", "error", err)
+			continue
+		}
+
+		l.wakeDispatcher()
+	}
+}
+
+func (l *Looper) SendDigests(ctx context.Context) {
+	ticker := time.NewTicker(TickDigest)
+	defer ticker.Stop()
+
+	log := slog.With("component", "looper.digest")
+	log.Info("starting loop", "tick", TickDigest)
+
+	for {
+		select {
+		case <-ticker.C:
+			subscriptions, err := l.db.FindSubscriptionsWithQueuedItems(ctx)
+			if err != nil {
+				log.Error("failed to find subscriptions with queued items", "error", err)
+				continue
+			}
+
+			for _, sub := range subscriptions {
 				log := log.With("subscription_id", sub.ID, "user_id", sub.UserID)
 
-				foundItems, err := l.gw.Search(ctx, sub.Term, opts...)
+				queued, err := l.db.FindQueuedItems(ctx, sub.ID)
 				if err != nil {
-					log.Error("failed to search for items", "error", err)
+					log.Error("failed to find queued items", "error", err)
 					continue
 				}
 
-				newItems := make([]gw.Item, 0, len(foundItems))
-				for _, item := range foundItems {
-					tracked, err := l.db.IsItemTracked(ctx, sqlgen.IsItemTrackedParams{
-						SubscriptionID: sub.ID,
-						GoodwillID:     item.ItemID,
-					})
-					if err != nil {
-						log.Error("failed to check if item is tracked", "error", err)
-						continue
-					}
+				if len(queued) == 0 {
+					continue
+				}
 
-					if tracked != 1 {
-						newItems = append(newItems, item)
-					}
+				settings, err := l.findUserSettings(ctx, sub.UserID)
+				if err != nil {
+					log.Error("failed to find user settings", "error", err)
+					continue
 				}
 
-				if len(newItems) == 0 {
-					log.Info("no new items found")
+				// queued items are ordered by when they were queued, so the first one decides when the digest is due
+				if time.Now().Before(notify.ScheduleFor(sub, settings).Next(queued[0].CreatedAt)) {
 					continue
 				}
 
-				log.Info("new items found", "count", len(newItems))
+				items := make([]gw.Item, 0, len(queued))
+				ids := make([]string, 0, len(queued))
+				for _, q := range queued {
+					ids = append(ids, q.ID)
 
-				for _, item := range newItems {
-					_, err := l.db.CreateItem(ctx, item.NewCreateItemParams(sub))
-					if err != nil {
-						log.Error("failed to create item", "error", err)
+					var item gw.Item
+					if err := json.Unmarshal([]byte(q.Item), &item); err != nil {
+						log.Error("failed to decode queued item", "error", err, "goodwill_id", q.GoodwillID)
 						continue
 					}
+
+					if !item.Ended() {
+						items = append(items, item)
+					}
 				}
 
-				if err := l.bot.NotifyNewItems(sub, newItems); err != nil {
-					log.Error("failed to notify new items", "error", err)
+				// queued items are only deleted once their digest is in the outbox
+				if err := l.db.WithTx(ctx, func(q sqlgen.Querier) error {
+					if len(items) > 0 {
+						n := notify.NewDigest(sub, items)
+						n.Location = notify.LocationFor(settings)
+						if err := l.enqueue(ctx, q, n); err != nil {
+							return err
+						}
+					}
+
+					return q.DeleteQueuedItems(ctx, ids)
+				}); err != nil {
+					log.Error("failed to send digest", "error", err)
+					continue
 				}
 
-				if err := l.db.SetSubscriptionLastNotifiedAt(ctx, sub.ID); err != nil {
-					log.Error("failed to set last notified at", "error", err)
+				if len(items) > 0 {
+					log.Info("sending digest", "count", len(items))
+					l.wakeDispatcher()
 				}
 			}
 		case <-ctx.Done():
@@ -102,58 +615,281 @@ func (l *Looper) NotifyNewItems(ctx context.Context) {
 	}
 }
 
-func (l *Looper) NotifyEndingSoonItems(ctx context.Context) {
-	ticker := time.NewTicker(TickNotifyEnding)
+// RefreshItems checks the items users were alerted about and watched items again for changes worth an alert, like
+// new bids. Items are due again 15 minutes after they were checked, but only a batch is checked each tick, so it
+// takes longer when there are many, see FindItemsToRefresh.
+func (l *Looper) RefreshItems(ctx context.Context) {
+	ticker := time.NewTicker(TickRefresh)
 	defer ticker.Stop()
 
-	log := slog.With("component", "looper.notify.ending")
-	log.Info("starting loop", "tick", TickNotifyEnding)
+	log := slog.With("component", "looper.refresh")
+	log.Info("starting loop", "tick", TickRefresh)
 
 	for {
 		select {
 		case <-ticker.C:
-			items, err := l.db.FindItemsEndingSoon(ctx)
+			if !l.available(log) {
+				continue
+			}
+
+			rows, err := l.db.FindItemsToRefresh(ctx)
 			if err != nil {
-				log.Error("failed to find items ending soon", "error", err)
+				log.Error("failed to find items to refresh", "error", err)
 				continue
 			}
 
-			sub2items := map[string][]sqlgen.Item{}
-			for _, item := range items {
-				sub2items[item.SubscriptionID] = append(sub2items[item.SubscriptionID], item)
+			// subscriptions can track the same item, it's only fetched once for all of them
+			goodwill2rows := map[int64][]sqlgen.FindItemsToRefreshRow{}
+			goodwillIDs := make([]int64, 0, len(rows))
+			for _, row := range rows {
+				id := row.Item.GoodwillID
+				if _, ok := goodwill2rows[id]; !ok {
+					goodwillIDs = append(goodwillIDs, id)
+				}
+				goodwill2rows[id] = append(goodwill2rows[id], row)
 			}
 
-			for subID, items := range sub2items {
-				log := log.With("subscription_id", subID)
+			for _, goodwillID := range goodwillIDs {
+				if !l.available(log) {
+					break
+				}
 
-				time.Sleep(2 * time.Second)
+				log := log.With("goodwill_id", goodwillID)
 
-				sub, err := l.db.FindSubscription(ctx, subID)
+				after, err := l.gw.FindItem(ctx, goodwillID)
 				if err != nil {
-					log.Error("failed to find subscription", "error", err)
+					log.Error("failed to refresh item", "error", err)
 					continue
 				}
 
-				log.Info("found ending soon items", "count", len(items))
-				if err := l.bot.NotifyEndingSoonItems(sub, items); err != nil {
-					log.Error("failed to notify ending soon items", "error", err)
+				for _, row := range goodwill2rows[goodwillID] {
+					item, sub := row.Item, row.Subscription
+					log := log.With("subscription_id", sub.ID, "user_id", sub.UserID)
+
+					if err := l.db.WithTx(ctx, func(q sqlgen.Querier) error {
+						// items tracked before their state was stored have nothing to compare to yet
+						if item.CheckedAt != nil {
+							if err := l.notifyItemChanges(ctx, log, q, sub, notify.ItemState{
+								CurrentPrice: item.CurrentPrice,
+								NumBids:      item.NumBids,
+								BuyNowPrice:  item.BuyNowPrice,
+							}, *after); err != nil {
+								return err
+							}
+						}
+
+						return q.SetItemChecked(ctx, sqlgen.SetItemCheckedParams{
+							CurrentPrice: after.CurrentPrice,
+							NumBids:      after.NumBids,
+							BuyNowPrice:  after.BuyNowPrice,
+							ID:           item.ID,
+						})
+					}); err != nil {
+						log.Error("failed to set item checked", "error", err)
+					}
 				}
+			}
+
+			watched, err := l.db.FindWatchedItemsToRefresh(ctx)
+			if err != nil {
+				log.Error("failed to find watched items to refresh", "error", err)
+				continue
+			}
 
-				itemIDs := make([]string, 0, len(items))
-				for _, item := range items {
-					itemIDs = append(itemIDs, item.ID)
+			for _, w := range watched {
+				if !l.available(log) {
+					break
 				}
 
-				if err := l.db.SetItemSentFinal(ctx, itemIDs); err != nil {
-					log.Error("failed to set item sent final notification", "error", err)
+				log := log.With("user_id", w.UserID, "goodwill_id", w.GoodwillID)
+
+				after, err := l.gw.FindItem(ctx, w.GoodwillID)
+				if err != nil {
+					log.Error("failed to refresh watched item", "error", err)
+					continue
+				}
+
+				if err := l.db.WithTx(ctx, func(q sqlgen.Querier) error {
+					// watched items aren't part of a subscription, so there's no max price
+					if w.CheckedAt != nil {
+						if err := l.notifyItemChanges(ctx, log, q, sqlgen.Subscription{UserID: w.UserID}, notify.ItemState{
+							CurrentPrice: w.CurrentPrice,
+							NumBids:      w.NumBids,
+							BuyNowPrice:  w.BuyNowPrice,
+						}, *after); err != nil {
+							return err
+						}
+					}
+
+					return q.SetWatchedItemChecked(ctx, sqlgen.SetWatchedItemCheckedParams{
+						CurrentPrice: after.CurrentPrice,
+						NumBids:      after.NumBids,
+						BuyNowPrice:  after.BuyNowPrice,
+						ID:           w.ID,
+					})
+				}); err != nil {
+					log.Error("failed to set watched item checked", "error", err)
 				}
 			}
+
+			l.wakeDispatcher()
 		case <-ctx.Done():
 			return
 		}
 	}
 }
 
+// notifyItemChanges adds an alert to the outbox with q for any changes to an item since before, its state when it
+// was last checked.
+func (l *Looper) notifyItemChanges(ctx context.Context, log *slog.Logger, q sqlgen.Querier, sub sqlgen.Subscription, before notify.ItemState, after gw.Item) error {
+	settings, err := l.findUserSettings(ctx, sub.UserID)
+	if err != nil {
+		log.Error("failed to find user settings", "error", err)
+	}
+
+	var bidJump int64
+	if settings != nil {
+		bidJump = settings.BidJump
+	}
+
+	changes := notify.ItemChanges(before, after, sub.MaxPrice, bidJump)
+	if len(changes) == 0 {
+		return nil
+	}
+
+	n := notify.Notification{
+		Kind:         notify.KindItemUpdate,
+		Subscription: sub,
+		Items:        []gw.Item{after},
+		Location:     notify.LocationFor(settings),
+		Changes:      changes,
+	}
+
+	if quiet := notify.QuietHoursFor(settings); quiet.Contains(time.Now()) {
+		end := quiet.EndAfter(time.Now()).UTC()
+		// there's no point in an update after the item ended, its sold report covers that
+		if after.EndTime.Before(end) {
+			log.Info("skipping item update during quiet hours, the item ends before they're over", "changes", changes)
+			return nil
+		}
+
+		log.Info("holding item update until quiet hours end", "changes", changes, "until", end)
+		return l.enqueueAt(ctx, q, n, &end)
+	}
+
+	log.Info("item changed", "changes", changes)
+
+	return l.enqueue(ctx, q, n)
+}
+
+// RecordResults stores the final price of items after they end, and sends sold reports to users that want them. Only
+// items alerted to those users are looked up, to save the rate limit for polling.
+func (l *Looper) RecordResults(ctx context.Context) {
+	ticker := time.NewTicker(TickResults)
+	defer ticker.Stop()
+
+	log := slog.With("component", "looper.results")
+	log.Info("starting loop", "tick", TickResults)
+
+	for {
+		select {
+		case <-ticker.C:
+			if !l.available(log) {
+				continue
+			}
+
+			ended, err := l.db.FindEndedItemsWithoutResult(ctx)
+			if err != nil {
+				log.Error("failed to find ended items", "error", err)
+				continue
+			}
+
+			for _, row := range ended {
+				if !l.available(log) {
+					break
+				}
+
+				goodwillID := row.GoodwillID
+				log := log.With("goodwill_id", goodwillID)
+
+				item, err := l.gw.FindItem(ctx, goodwillID)
+				if err != nil {
+					l.setResultFailed(ctx, log, goodwillID, row.Attempts+1, err)
+					continue
+				}
+
+				subscriptions, err := l.db.FindSoldReportSubscriptions(ctx, item.ItemID)
+				if err != nil {
+					log.Error("failed to find subscriptions for sold report", "error", err)
+					continue
+				}
+
+				// reports are only sent if the result is recorded, otherwise they're sent again next time
+				if err := l.db.WithTx(ctx, func(q sqlgen.Querier) error {
+					if _, err := q.CreateItemResult(ctx, sqlgen.CreateItemResultParams{
+						GoodwillID: item.ItemID,
+						Title:      item.Title,
+						FinalPrice: item.CurrentPrice,
+						NumBids:    item.NumBids,
+						EndedAt:    item.EndTime,
+					}); err != nil {
+						return err
+					}
+
+					// the same item can be in more than one of the user's subscriptions, but it's only reported once
+					reported := map[string]bool{}
+					for _, sub := range subscriptions {
+						if reported[sub.UserID] {
+							continue
+						}
+						reported[sub.UserID] = true
+
+						if err := l.enqueue(ctx, q, notify.Notification{
+							Kind:         notify.KindSold,
+							Subscription: sub,
+							Items:        []gw.Item{*item},
+						}); err != nil {
+							return err
+						}
+					}
+
+					return nil
+				}); err != nil {
+					log.Error("failed to create item result", "error", err)
+					continue
+				}
+
+				log.Info("recorded item result", "final_price", item.CurrentPrice, "num_bids", item.NumBids)
+			}
+
+			l.wakeDispatcher()
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// setResultFailed records a failed attempt at an item's result, so it's tried again later with backoff instead of
+// holding up the rest. Items that were removed from ShopGoodwill or failed too often are given up on.
+func (l *Looper) setResultFailed(ctx context.Context, log *slog.Logger, goodwillID, attempts int64, err error) {
+	final := errors.Is(err, gw.ErrNotFound) || attempts >= MaxResultAttempts
+	if final {
+		log.Warn("failed to find item, giving up on its result", "error", err, "attempts", attempts)
+	} else {
+		log.Error("failed to find item", "error", err, "attempts", attempts)
+	}
+
+	backoff := min(ResultsInitialBackoff<<(attempts-1), ResultsMaxBackoff)
+	if err := l.db.SetItemResultFailed(ctx, sqlgen.SetItemResultFailedParams{
+		GoodwillID:    goodwillID,
+		LastError:     err.Error(),
+		Final:         final,
+		NextAttemptAt: time.Now().Add(backoff).UTC(),
+	}); err != nil {
+		log.Error("failed to set item result failed", "error", err)
+	}
+}
+
 func (l *Looper) Cleanup(ctx context.Context) {
 	ticker := time.NewTicker(TickCleanup)
 	defer ticker.Stop()
@@ -169,8 +905,164 @@ func (l *Looper) Cleanup(ctx context.Context) {
 				log.Error("failed to delete expired items", "error", err)
 			}
 			log.Info("deleted expired items")
+
+			if err := l.db.DeleteExpiredWatchedItems(ctx); err != nil {
+				log.Error("failed to delete expired watched items", "error", err)
+			}
+
+			if err := l.db.DeleteExpiredAlertMessages(ctx); err != nil {
+				log.Error("failed to delete expired alert messages", "error", err)
+			}
+
+			if err := l.db.DeleteExpiredSearches(ctx); err != nil {
+				log.Error("failed to delete expired searches", "error", err)
+			}
+
+			if err := l.db.DeleteExpiredOutboxMessages(ctx); err != nil {
+				log.Error("failed to delete expired outbox messages", "error", err)
+			}
+
+			if err := l.db.DeleteOrphanedItemReminders(ctx); err != nil {
+				log.Error("failed to delete orphaned item reminders", "error", err)
+			}
+
+			if err := l.db.DeleteOrphanedItemResultFailures(ctx); err != nil {
+				log.Error("failed to delete orphaned item result failures", "error", err)
+			}
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// LogWaitStats logs how long requests to ShopGoodwill waited for the rate limiter, to tell if it's set too low.
+func (l *Looper) LogWaitStats(ctx context.Context) {
+	ticker := time.NewTicker(TickWaitStats)
+	defer ticker.Stop()
+
+	log := slog.With("component", "looper.wait_stats")
+	log.Info("starting loop", "tick", TickWaitStats)
+
+	for {
+		select {
+		case <-ticker.C:
+			for priority, stats := range l.gw.WaitStats() {
+				if stats.Requests == 0 {
+					continue
+				}
+
+				log.Info("rate limiter wait",
+					"priority", priority.String(),
+					"requests", stats.Requests,
+					"avg", stats.Average(),
+					"max", stats.Max,
+				)
+			}
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+func (l *Looper) Resume(ctx context.Context) {
+	ticker := time.NewTicker(TickResume)
+	defer ticker.Stop()
+
+	log := slog.With("component", "looper.resume")
+	log.Info("starting loop", "tick", TickResume)
+
+	for {
+		select {
+		case <-ticker.C:
+			subscriptions, err := l.db.FindSubscriptionsToResume(ctx)
+			if err != nil {
+				log.Error("failed to find subscriptions to resume", "error", err)
+				continue
+			}
+
+			for _, sub := range subscriptions {
+				log := log.With("subscription_id", sub.ID, "user_id", sub.UserID)
+
+				n, err := l.tracker.Resume(ctx, sub)
+				if err != nil {
+					log.Error("failed to resume subscription", "error", err)
+					continue
+				}
+
+				log.Info("resumed subscription", "seeded", n)
+			}
+
+			vacations, err := l.db.FindExpiredVacations(ctx)
+			if err != nil {
+				log.Error("failed to find expired vacations", "error", err)
+				continue
+			}
+
+			for _, vacation := range vacations {
+				log := log.With("user_id", vacation.UserID)
+
+				n, err := l.tracker.EndVacation(ctx, vacation.UserID)
+				if err != nil {
+					log.Error("failed to end vacation", "error", err)
+					continue
+				}
+
+				log.Info("ended vacation", "seeded", n)
+			}
 		case <-ctx.Done():
 			return
 		}
 	}
 }
+
+func (l *Looper) setRemindersSent(ctx context.Context, log *slog.Logger, reminders []sqlgen.FindDueRemindersRow) {
+	for _, reminder := range reminders {
+		l.setReminderSent(ctx, log, reminder.Item.ID, reminder.Minutes)
+	}
+}
+
+// setReminderSent marks a reminder stage as sent, for both subscription items and watched items.
+func (l *Looper) setReminderSent(ctx context.Context, log *slog.Logger, itemID string, minutes int64) {
+	if err := l.db.SetItemReminderSent(ctx, sqlgen.SetItemReminderSentParams{
+		ItemID:  itemID,
+		Minutes: minutes,
+	}); err != nil {
+		log.Error("failed to set item reminder sent", "error", err, "item_id", itemID, "minutes", minutes)
+	}
+}
+
+// available reports if ShopGoodwill can be called, so loops can skip their work while it's down instead of failing
+// for every subscription.
+func (l *Looper) available(log *slog.Logger) bool {
+	if l.gw.Available() {
+		return true
+	}
+
+	log.Warn("ShopGoodwill is unavailable, skipping until the next tick")
+	return false
+}
+
+// findUserSettings returns nil if the user never changed their settings.
+func (l *Looper) findUserSettings(ctx context.Context, userID string) (*sqlgen.UserSetting, error) {
+	settings, err := l.db.FindUserSettings(ctx, userID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+	return &settings, nil
+}
+
+func queueItem(ctx context.Context, q sqlgen.Querier, sub sqlgen.Subscription, item gw.Item) error {
+	b, err := json.Marshal(item)
+	if err != nil {
+		return err
+	}
+
+	return q.QueueItem(ctx, sqlgen.QueueItemParams{
+		ID:             db.NewID(),
+		SubscriptionID: sub.ID,
+		GoodwillID:     item.ItemID,
+		Item:           string(b),
+	})
+}
@@ -0,0 +1,296 @@
+package looper
+
+import (
+	"context"
+	"errors"
+	"iter"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/pressly/goose/v3"
+	"github.com/robherley/gw-bot/internal/db"
+	"github.com/robherley/gw-bot/internal/db/sqlgen"
+	"github.com/robherley/gw-bot/internal/gw"
+	"github.com/robherley/gw-bot/internal/notify"
+	"github.com/robherley/gw-bot/internal/tracker"
+)
+
+// fakeClient stands in for ShopGoodwill, serving items from memory.
+type fakeClient struct {
+	items []gw.Item
+}
+
+func (c *fakeClient) Search(ctx context.Context, term string, opts ...gw.SearchOption) ([]gw.Item, error) {
+	return c.items, nil
+}
+
+func (c *fakeClient) Pages(ctx context.Context, term string, maxPages int, opts ...gw.SearchOption) iter.Seq2[[]gw.Item, error] {
+	return func(yield func([]gw.Item, error) bool) {
+		yield(c.items, nil)
+	}
+}
+
+func (c *fakeClient) FindItem(ctx context.Context, id int64) (*gw.Item, error) {
+	for _, item := range c.items {
+		if item.ItemID == id {
+			return &item, nil
+		}
+	}
+	return nil, gw.ErrNotFound
+}
+
+func (c *fakeClient) Available() bool {
+	return true
+}
+
+func (c *fakeClient) WaitStats() map[gw.Priority]gw.WaitStats {
+	return nil
+}
+
+func TestNotifyNewItems(t *testing.T) {
+	ctx := context.Background()
+	client := &fakeClient{items: []gw.Item{
+		item(2, "brass lamp", 24*time.Hour),
+		item(1, "floor lamp", 24*time.Hour),
+	}}
+	l, d, recorder := setup(t, client)
+	sub := subscribe(t, d, "lamp")
+
+	// the first item is already tracked, so only the one listed after it is new
+	if err := tracker.New(d, client, 1).Track(ctx, sub, client.items[1:]); err != nil {
+		t.Fatal(err)
+	}
+
+	groups := l.tracker.Group(ctx, []sqlgen.Subscription{sub}, gw.WithDescending(true))
+	defer groups[0].Close()
+
+	found, err := l.notifyNewItems(ctx, slog.Default(), groups[0], sub)
+	if err != nil {
+		t.Fatalf("notifyNewItems() error = %v", err)
+	}
+
+	if found != 1 {
+		t.Errorf("notifyNewItems() found %d items, want 1", found)
+	}
+
+	// nothing is sent until the outbox is dispatched
+	if got := recorder.Notifications(); len(got) != 0 {
+		t.Fatalf("got %d notifications before dispatching, want 0", len(got))
+	}
+
+	l.dispatchDue(ctx, slog.Default())
+
+	assertNotified(t, recorder, notify.KindNewItems, 2)
+
+	// the new item is tracked along with its notification, so it isn't sent again
+	recorder.Reset()
+	groups = l.tracker.Group(ctx, []sqlgen.Subscription{sub}, gw.WithDescending(true))
+	defer groups[0].Close()
+
+	if found, err := l.notifyNewItems(ctx, slog.Default(), groups[0], sub); err != nil || found != 0 {
+		t.Fatalf("notifyNewItems() = %d, %v, want 0, nil", found, err)
+	}
+
+	l.dispatchDue(ctx, slog.Default())
+
+	if got := recorder.Notifications(); len(got) != 0 {
+		t.Errorf("got %d notifications for tracked items, want 0", len(got))
+	}
+}
+
+func TestNotifyEndingSoonItems(t *testing.T) {
+	ctx := context.Background()
+	client := &fakeClient{items: []gw.Item{
+		item(1, "floor lamp", 5*time.Minute),
+		item(2, "brass lamp", 24*time.Hour),
+	}}
+	l, d, recorder := setup(t, client)
+	sub := subscribe(t, d, "lamp")
+
+	if err := l.tracker.Track(ctx, sub, client.items); err != nil {
+		t.Fatal(err)
+	}
+
+	l.notifyEndingSoonItems(ctx, slog.Default())
+	l.dispatchDue(ctx, slog.Default())
+
+	// only the item ending within the subscription's reminder is sent
+	assertNotified(t, recorder, notify.KindEndingSoon, 1)
+
+	// the reminder is marked sent, so it isn't sent again
+	recorder.Reset()
+	l.notifyEndingSoonItems(ctx, slog.Default())
+	l.dispatchDue(ctx, slog.Default())
+
+	if got := recorder.Notifications(); len(got) != 0 {
+		t.Errorf("got %d notifications for sent reminders, want 0", len(got))
+	}
+}
+
+func TestNotifyEndsQualifier(t *testing.T) {
+	ctx := context.Background()
+	client := &fakeClient{items: []gw.Item{
+		item(2, "brass lamp", 24*time.Hour),
+		item(1, "floor lamp", 5*time.Minute),
+	}}
+	l, d, recorder := setup(t, client)
+	sub := subscribe(t, d, "lamp ends:<2h")
+
+	groups := l.tracker.Group(ctx, []sqlgen.Subscription{sub}, gw.WithDescending(true))
+	defer groups[0].Close()
+
+	// both items are tracked, but only the one ending soon matches yet
+	found, err := l.notifyNewItems(ctx, slog.Default(), groups[0], sub)
+	if err != nil {
+		t.Fatalf("notifyNewItems() error = %v", err)
+	}
+
+	if found != 2 {
+		t.Errorf("notifyNewItems() found %d items, want 2", found)
+	}
+
+	l.dispatchDue(ctx, slog.Default())
+	assertNotified(t, recorder, notify.KindNewItems, 1)
+
+	// the other item is sent by its reminder once it's ending in less than 2h
+	recorder.Reset()
+	client.items[0].EndTime = time.Now().Add(5 * time.Minute).UTC()
+	if _, err := d.(*db.SQLite).ExecContext(ctx, "UPDATE items SET ends_at = ? WHERE goodwill_id = 2", client.items[0].EndTime); err != nil {
+		t.Fatal(err)
+	}
+
+	l.notifyEndingSoonItems(ctx, slog.Default())
+	l.dispatchDue(ctx, slog.Default())
+	assertNotified(t, recorder, notify.KindEndingSoon, 1, 2)
+}
+
+// setup returns a looper with a temp database, sending notifications to the returned recorder.
+func setup(t *testing.T, client *fakeClient) (*Looper, db.DB, *notify.Recorder) {
+	t.Helper()
+
+	d, err := db.NewSQLite(filepath.Join(t.TempDir(), "looper.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { d.Close() })
+
+	goose.SetLogger(goose.NopLogger())
+	if err := d.Migrate(context.Background(), os.DirFS("../..")); err != nil {
+		t.Fatal(err)
+	}
+
+	recorder := notify.NewRecorder()
+	l := New(d, client, tracker.New(d, client, 1), 1, PollIntervals{Min: time.Minute, Max: time.Hour}, notify.Sink{Name: "recorder", Notifier: recorder})
+	return l, d, recorder
+}
+
+func subscribe(t *testing.T, d db.DB, term string) sqlgen.Subscription {
+	t.Helper()
+
+	sub, err := d.CreateSubscription(context.Background(), sqlgen.CreateSubscriptionParams{
+		ID:        db.NewID(),
+		UserID:    "user",
+		Term:      term,
+		Reminders: "10",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return sub
+}
+
+func item(id int64, title string, endsIn time.Duration) gw.Item {
+	return gw.Item{
+		ItemID:    id,
+		Title:     title,
+		StartTime: time.Now().Add(-time.Hour).UTC(),
+		EndTime:   time.Now().Add(endsIn).UTC(),
+	}
+}
+
+// assertNotified checks that exactly one notification of kind was sent, for the items with ids in ascending order.
+func assertNotified(t *testing.T, recorder *notify.Recorder, kind notify.Kind, ids ...int64) {
+	t.Helper()
+
+	got := recorder.Notifications()
+	if len(got) != 1 {
+		t.Fatalf("got %d notifications, want 1", len(got))
+	}
+
+	if got[0].Kind != kind {
+		t.Errorf("notification kind = %q, want %q", got[0].Kind, kind)
+	}
+
+	itemIDs := make([]int64, 0, len(got[0].Items))
+	for _, item := range got[0].Items {
+		itemIDs = append(itemIDs, item.ItemID)
+	}
+	slices.Sort(itemIDs)
+
+	if !slices.Equal(itemIDs, ids) {
+		t.Errorf("notified items = %v, want %v", itemIDs, ids)
+	}
+}
+
+// targeted records the deliveries to each of its targets, failing the targets in fail.
+type targeted struct {
+	notify.Recorder
+	fail       map[string]bool
+	deliveries map[string][]string
+}
+
+func (n *targeted) Targets(ctx context.Context, q sqlgen.Querier, _ notify.Notification) ([]string, error) {
+	return []string{"a", "b"}, nil
+}
+
+func (n *targeted) NotifyTarget(ctx context.Context, _ notify.Notification, target, deliveryID string) error {
+	n.deliveries[target] = append(n.deliveries[target], deliveryID)
+	if n.fail[target] {
+		return errors.New("unavailable")
+	}
+	return nil
+}
+
+func TestDispatchTargets(t *testing.T) {
+	ctx := context.Background()
+	l, d, recorder := setup(t, &fakeClient{})
+	hooks := &targeted{fail: map[string]bool{"b": true}, deliveries: map[string][]string{}}
+	l.sinks = append(l.sinks, notify.Sink{Name: "hooks", Notifier: hooks})
+
+	sub := subscribe(t, d, "lamp")
+	if err := d.WithTx(ctx, func(q sqlgen.Querier) error {
+		return l.enqueue(ctx, q, notify.Notification{Kind: notify.KindNewItems, Subscription: sub})
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	l.dispatchDue(ctx, slog.Default())
+
+	// retry the failed target right away
+	hooks.fail["b"] = false
+	if _, err := d.(*db.SQLite).ExecContext(ctx, "UPDATE outbox_messages SET next_attempt_at = CURRENT_TIMESTAMP"); err != nil {
+		t.Fatal(err)
+	}
+	l.dispatchDue(ctx, slog.Default())
+
+	if got := len(recorder.Notifications()); got != 1 {
+		t.Errorf("got %d notifications for the untargeted sink, want 1", got)
+	}
+
+	// only the failed target is sent again, with the same delivery ID
+	if got := hooks.deliveries["a"]; len(got) != 1 {
+		t.Errorf("target a got %d deliveries, want 1", len(got))
+	}
+
+	if got := hooks.deliveries["b"]; len(got) != 2 || got[0] != got[1] {
+		t.Errorf("target b got deliveries %v, want the same delivery twice", got)
+	}
+
+	if hooks.deliveries["a"][0] == hooks.deliveries["b"][0] {
+		t.Errorf("targets got the same delivery ID %q", hooks.deliveries["a"][0])
+	}
+}
@@ -0,0 +1,179 @@
+package looper
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/robherley/gw-bot/internal/db"
+	"github.com/robherley/gw-bot/internal/db/sqlgen"
+	"github.com/robherley/gw-bot/internal/notify"
+)
+
+const (
+	TickOutbox = 15 * time.Second
+
+	// MaxOutboxAttempts is how many times a notification is tried before it's dead lettered.
+	MaxOutboxAttempts    = 8
+	OutboxInitialBackoff = 30 * time.Second
+	OutboxMaxBackoff     = 1 * time.Hour
+)
+
+// DispatchOutbox sends the notifications in the outbox, retrying failed ones with backoff. Notifications are sent at
+// least once to each sink, even across restarts.
+func (l *Looper) DispatchOutbox(ctx context.Context) {
+	ticker := time.NewTicker(TickOutbox)
+	defer ticker.Stop()
+
+	log := slog.With("component", "looper.outbox")
+	log.Info("starting loop", "tick", TickOutbox)
+
+	for {
+		select {
+		case <-ticker.C:
+		case <-l.wake:
+		case <-ctx.Done():
+			return
+		}
+
+		l.dispatchDue(ctx, log)
+	}
+}
+
+// dispatchDue sends the outbox messages that are due.
+func (l *Looper) dispatchDue(ctx context.Context, log *slog.Logger) {
+	messages, err := l.db.FindDueOutboxMessages(ctx)
+	if err != nil {
+		log.Error("failed to find due outbox messages", "error", err)
+		return
+	}
+
+	for _, msg := range messages {
+		if ctx.Err() != nil {
+			return
+		}
+
+		l.dispatch(ctx, log.With("outbox_message_id", msg.ID, "user_id", msg.UserID, "kind", msg.Kind, "sink", msg.Sink, "target", msg.Target), msg)
+	}
+}
+
+func (l *Looper) dispatch(ctx context.Context, log *slog.Logger, msg sqlgen.OutboxMessage) {
+	notifier, ok := l.sink(msg.Sink)
+	if !ok {
+		// like email without an SMTP server anymore
+		log.Error("failed to find sink, giving up")
+		if err := l.db.SetOutboxMessageDead(ctx, sqlgen.SetOutboxMessageDeadParams{
+			LastError: fmt.Sprintf("unknown sink %q", msg.Sink),
+			ID:        msg.ID,
+		}); err != nil {
+			log.Error("failed to set outbox message dead", "error", err)
+		}
+		return
+	}
+
+	n, err := notify.DecodeNotification(msg.Notification)
+	if err == nil {
+		if targeted, ok := notifier.(notify.Targeted); ok {
+			// the message is the same on every retry, so it's used as the delivery ID
+			err = targeted.NotifyTarget(ctx, n, msg.Target, msg.ID)
+		} else {
+			err = notifier.Notify(ctx, n)
+		}
+	}
+
+	if err == nil {
+		if err := l.db.SetOutboxMessageSent(ctx, msg.ID); err != nil {
+			log.Error("failed to set outbox message sent", "error", err)
+		}
+		return
+	}
+
+	// shutting down, it's sent again after a restart
+	if ctx.Err() != nil {
+		return
+	}
+
+	attempts := msg.Attempts + 1
+	if attempts >= MaxOutboxAttempts || !notify.Retryable(err) {
+		log.Error("failed to send notification, giving up", "error", err, "attempts", attempts)
+		if err := l.db.SetOutboxMessageDead(ctx, sqlgen.SetOutboxMessageDeadParams{
+			LastError: err.Error(),
+			ID:        msg.ID,
+		}); err != nil {
+			log.Error("failed to set outbox message dead", "error", err)
+		}
+		return
+	}
+
+	backoff := min(OutboxInitialBackoff<<(attempts-1), OutboxMaxBackoff)
+	log.Warn("failed to send notification, retrying", "error", err, "attempts", attempts, "backoff", backoff)
+
+	if err := l.db.SetOutboxMessageFailed(ctx, sqlgen.SetOutboxMessageFailedParams{
+		LastError:     err.Error(),
+		NextAttemptAt: time.Now().Add(backoff).UTC(),
+		ID:            msg.ID,
+	}); err != nil {
+		log.Error("failed to set outbox message failed", "error", err)
+	}
+}
+
+// sink returns the notifier of an outbox message, or false if it's not configured.
+func (l *Looper) sink(name string) (notify.Notifier, bool) {
+	for _, sink := range l.sinks {
+		if sink.Name == name {
+			return sink.Notifier, true
+		}
+	}
+
+	return nil, false
+}
+
+// enqueue adds a notification to the outbox with q for each sink, or each target of targeted sinks, so it's only sent
+// if the rest of the transaction commits. Call wakeDispatcher after committing to send it right away.
+func (l *Looper) enqueue(ctx context.Context, q sqlgen.Querier, n notify.Notification) error {
+	return l.enqueueAt(ctx, q, n, nil)
+}
+
+// enqueueAt is like enqueue, but the notification isn't sent before at, like the end of quiet hours. It's sent right
+// away when at is nil.
+func (l *Looper) enqueueAt(ctx context.Context, q sqlgen.Querier, n notify.Notification, at *time.Time) error {
+	encoded, err := notify.EncodeNotification(n)
+	if err != nil {
+		return err
+	}
+
+	for _, sink := range l.sinks {
+		targets := []string{""}
+		if targeted, ok := sink.Notifier.(notify.Targeted); ok {
+			if targets, err = targeted.Targets(ctx, q, n); err != nil {
+				return err
+			}
+		}
+
+		for _, target := range targets {
+			if err := q.CreateOutboxMessage(ctx, sqlgen.CreateOutboxMessageParams{
+				ID:             db.NewID(),
+				UserID:         n.Subscription.UserID,
+				SubscriptionID: n.Subscription.ID,
+				Kind:           string(n.Kind),
+				Notification:   encoded,
+				Sink:           sink.Name,
+				Target:         target,
+				NextAttemptAt:  at,
+			}); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// wakeDispatcher sends the outbox right away instead of on the next tick.
+func (l *Looper) wakeDispatcher() {
+	select {
+	case l.wake <- struct{}{}:
+	default:
+	}
+}
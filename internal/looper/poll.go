@@ -0,0 +1,34 @@
+package looper
+
+import (
+	"time"
+
+	"github.com/robherley/gw-bot/internal/db/sqlgen"
+)
+
+// PollIntervals bounds how often each subscription is searched for new items. Intervals shorter than TickNotifyNew
+// are searched every tick.
+type PollIntervals struct {
+	Min time.Duration
+	Max time.Duration
+}
+
+// Next returns how long until a subscription is searched again, after it found some new items. Subscriptions that
+// find new items are searched more often and ones that don't back off, priority subscriptions are always searched as
+// often as allowed.
+func (p PollIntervals) Next(sub sqlgen.Subscription, found int) time.Duration {
+	if sub.Priority {
+		return p.Min
+	}
+
+	current := time.Duration(sub.PollInterval) * time.Second
+	if found > 0 {
+		return p.Clamp(current / 2)
+	}
+	return p.Clamp(current * 3 / 2)
+}
+
+// Clamp keeps the interval within the bounds, which can change after subscriptions were last searched.
+func (p PollIntervals) Clamp(d time.Duration) time.Duration {
+	return min(max(d, p.Min), max(p.Max, p.Min))
+}
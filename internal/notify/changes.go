@@ -0,0 +1,37 @@
+package notify
+
+import (
+	"fmt"
+
+	"github.com/robherley/gw-bot/internal/gw"
+)
+
+// ItemState is what was seen of an item the last time it was checked.
+type ItemState struct {
+	CurrentPrice float64
+	NumBids      int64
+	BuyNowPrice  float64
+}
+
+// ItemChanges describes what changed about an item that's worth an alert: the price going over the max price, at
+// least bidJump new bids (zero turns these off) or a lower buy now price. The max price is nil for watched items.
+func ItemChanges(before ItemState, after gw.Item, maxPrice *int64, bidJump int64) []string {
+	changes := make([]string, 0)
+
+	if maxPrice != nil {
+		max := float64(*maxPrice)
+		if before.CurrentPrice <= max && after.CurrentPrice > max {
+			changes = append(changes, fmt.Sprintf("price is over your max of $%d at $%.2f", *maxPrice, after.CurrentPrice))
+		}
+	}
+
+	if bids := after.NumBids - before.NumBids; bidJump > 0 && bids >= bidJump {
+		changes = append(changes, fmt.Sprintf("%d new bids, now at $%.2f", bids, after.CurrentPrice))
+	}
+
+	if after.HasBuyNow() && before.BuyNowPrice > 0 && after.BuyNowPrice > 0 && after.BuyNowPrice < before.BuyNowPrice {
+		changes = append(changes, fmt.Sprintf("buy now price dropped from $%.2f to $%.2f", before.BuyNowPrice, after.BuyNowPrice))
+	}
+
+	return changes
+}
@@ -0,0 +1,121 @@
+package notify
+
+import (
+	"slices"
+	"time"
+
+	"github.com/robherley/gw-bot/internal/db/sqlgen"
+	"github.com/robherley/gw-bot/internal/gw"
+)
+
+// Delivery is how new items are sent for a subscription.
+type Delivery string
+
+const (
+	DeliveryInstant Delivery = "instant"
+	DeliveryHourly  Delivery = "hourly"
+	DeliveryDaily   Delivery = "daily"
+)
+
+const (
+	// DigestMaxItems is the most items shown in a digest, the rest are only counted.
+	DigestMaxItems = 10
+
+	DefaultDigestHour = 9
+)
+
+func (d Delivery) Valid() bool {
+	switch d {
+	case DeliveryInstant, DeliveryHourly, DeliveryDaily:
+		return true
+	default:
+		return false
+	}
+}
+
+// DigestSchedule is when new items are sent for a subscription.
+type DigestSchedule struct {
+	Delivery Delivery
+	// Hour is the hour of the day that daily digests are sent, in Location.
+	Hour     int
+	Location *time.Location
+	Quiet    *QuietHours
+}
+
+// ScheduleFor resolves the schedule of a subscription, which falls back to the user's settings. The settings are nil
+// if the user never changed any.
+func ScheduleFor(sub sqlgen.Subscription, settings *sqlgen.UserSetting) DigestSchedule {
+	schedule := DigestSchedule{
+		Delivery: DeliveryInstant,
+		Hour:     DefaultDigestHour,
+		Location: LocationFor(settings),
+		Quiet:    QuietHoursFor(settings),
+	}
+
+	if settings != nil {
+		schedule.Hour = int(settings.DigestHour)
+		if d := Delivery(settings.Delivery); d.Valid() {
+			schedule.Delivery = d
+		}
+	}
+
+	if d := Delivery(sub.Delivery); d.Valid() {
+		schedule.Delivery = d
+	}
+
+	return schedule
+}
+
+// Next returns when items queued since the given time should be sent, which is never during quiet hours.
+func (s DigestSchedule) Next(since time.Time) time.Time {
+	loc := s.Location
+	if loc == nil {
+		loc = time.UTC
+	}
+	since = since.In(loc)
+
+	var next time.Time
+	switch s.Delivery {
+	case DeliveryHourly:
+		next = since.Truncate(time.Hour).Add(time.Hour)
+	case DeliveryDaily:
+		next = time.Date(since.Year(), since.Month(), since.Day(), s.Hour, 0, 0, 0, loc)
+		if !next.After(since) {
+			next = next.AddDate(0, 0, 1)
+		}
+	default:
+		next = since
+	}
+
+	if s.Quiet.Contains(next) {
+		next = s.Quiet.EndAfter(next)
+	}
+
+	return next
+}
+
+// Holds reports if new items found at t have to be queued instead of sent right away.
+func (s DigestSchedule) Holds(t time.Time) bool {
+	return s.Delivery != DeliveryInstant || s.Quiet.Contains(t)
+}
+
+// NewDigest summarizes the items of a subscription, keeping the ones ending soonest and counting the rest.
+func NewDigest(sub sqlgen.Subscription, items []gw.Item) Notification {
+	items = slices.Clone(items)
+	slices.SortFunc(items, func(a, b gw.Item) int {
+		return a.EndTime.Compare(b.EndTime)
+	})
+
+	n := Notification{
+		Kind:         KindDigest,
+		Subscription: sub,
+		Items:        items,
+	}
+
+	if len(items) > DigestMaxItems {
+		n.Items = items[:DigestMaxItems]
+		n.Overflow = len(items) - DigestMaxItems
+	}
+
+	return n
+}
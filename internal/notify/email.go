@@ -0,0 +1,279 @@
+package notify
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"crypto/subtle"
+	"crypto/tls"
+	"database/sql"
+	"errors"
+	"fmt"
+	htmltemplate "html/template"
+	"math/big"
+	"mime"
+	"mime/multipart"
+	"mime/quotedprintable"
+	"net"
+	"net/smtp"
+	"net/textproto"
+	"strconv"
+	"strings"
+	texttemplate "text/template"
+	"time"
+
+	"github.com/robherley/gw-bot/internal/db"
+	"github.com/robherley/gw-bot/internal/db/sqlgen"
+	"github.com/robherley/gw-bot/internal/gw"
+)
+
+const (
+	// EmailCodeTTL is how long a verification code can be used for, a new one can't be sent before then.
+	EmailCodeTTL = 15 * time.Minute
+	// MaxEmailCodeAttempts is how many codes can be tried before the pending code stops working, so it can't be guessed.
+	MaxEmailCodeAttempts = 5
+	// EmailTimeout is how long sending an email can take, if the context doesn't have an earlier deadline.
+	EmailTimeout = 30 * time.Second
+)
+
+type SMTPConfig struct {
+	Host     string
+	Port     int
+	Username string
+	Password string
+	From     string
+}
+
+// Email sends notifications to the verified email address of the subscription's user.
+type Email struct {
+	db  db.DB
+	cfg SMTPConfig
+}
+
+func NewEmail(db db.DB, cfg SMTPConfig) *Email {
+	return &Email{db, cfg}
+}
+
+func (e *Email) Notify(ctx context.Context, n Notification) error {
+	settings, err := e.db.FindUserSettings(ctx, n.Subscription.UserID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil
+	} else if err != nil {
+		return err
+	}
+
+	if settings.Email == nil {
+		return nil
+	}
+
+	var text, html bytes.Buffer
+	if err := emailTextTemplate.Execute(&text, n); err != nil {
+		return err
+	}
+
+	if err := emailHTMLTemplate.Execute(&html, n); err != nil {
+		return err
+	}
+
+	return e.send(ctx, *settings.Email, n.Summary(), text.String(), html.String())
+}
+
+// SendCode emails the one time code used to verify that the user owns the address.
+func (e *Email) SendCode(ctx context.Context, to, code string) error {
+	text := fmt.Sprintf("Your gw-bot verification code is %s, it expires in %d minutes.\n\nIf you didn't ask for this, you can ignore this email.\n", code, int(EmailCodeTTL.Minutes()))
+	html := fmt.Sprintf("<p>Your gw-bot verification code is <strong>%s</strong>, it expires in %d minutes.</p><p>If you didn't ask for this, you can ignore this email.</p>", code, int(EmailCodeTTL.Minutes()))
+
+	return e.send(ctx, to, "gw-bot verification code", text, html)
+}
+
+// send is like smtp.SendMail, but gives up once the context is done or after EmailTimeout.
+func (e *Email) send(ctx context.Context, to, subject, text, html string) error {
+	msg, err := NewEmailMessage(e.cfg.From, to, subject, text, html)
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, EmailTimeout)
+	defer cancel()
+
+	dialer := &net.Dialer{}
+	conn, err := dialer.DialContext(ctx, "tcp", net.JoinHostPort(e.cfg.Host, strconv.Itoa(e.cfg.Port)))
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	deadline, _ := ctx.Deadline()
+	if err := conn.SetDeadline(deadline); err != nil {
+		return err
+	}
+
+	// unblock reads and writes right away if the context is canceled before the deadline
+	stop := context.AfterFunc(ctx, func() {
+		_ = conn.SetDeadline(time.Now())
+	})
+	defer stop()
+
+	c, err := smtp.NewClient(conn, e.cfg.Host)
+	if err != nil {
+		return err
+	}
+	defer c.Close()
+
+	if ok, _ := c.Extension("STARTTLS"); ok {
+		if err := c.StartTLS(&tls.Config{ServerName: e.cfg.Host}); err != nil {
+			return err
+		}
+	}
+
+	if e.cfg.Username != "" {
+		if err := c.Auth(smtp.PlainAuth("", e.cfg.Username, e.cfg.Password, e.cfg.Host)); err != nil {
+			return err
+		}
+	}
+
+	if err := c.Mail(e.cfg.From); err != nil {
+		return err
+	}
+
+	if err := c.Rcpt(to); err != nil {
+		return err
+	}
+
+	w, err := c.Data()
+	if err != nil {
+		return err
+	}
+
+	if _, err := w.Write(msg); err != nil {
+		return err
+	}
+
+	if err := w.Close(); err != nil {
+		return err
+	}
+
+	return c.Quit()
+}
+
+// NewEmailMessage builds a multipart/alternative message with both plain text and HTML bodies.
+func NewEmailMessage(from, to, subject, text, html string) ([]byte, error) {
+	var body bytes.Buffer
+	mw := multipart.NewWriter(&body)
+
+	for _, part := range []struct {
+		contentType string
+		content     string
+	}{
+		{"text/plain; charset=utf-8", text},
+		{"text/html; charset=utf-8", html},
+	} {
+		w, err := mw.CreatePart(textproto.MIMEHeader{
+			"Content-Type":              {part.contentType},
+			"Content-Transfer-Encoding": {"quoted-printable"},
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		qw := quotedprintable.NewWriter(w)
+		if _, err := qw.Write([]byte(part.content)); err != nil {
+			return nil, err
+		}
+
+		if err := qw.Close(); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := mw.Close(); err != nil {
+		return nil, err
+	}
+
+	var msg bytes.Buffer
+	headers := [][2]string{
+		{"From", from},
+		{"To", to},
+		{"Subject", mime.QEncoding.Encode("utf-8", subject)},
+		{"Date", time.Now().Format(time.RFC1123Z)},
+		{"MIME-Version", "1.0"},
+		{"Content-Type", "multipart/alternative; boundary=" + mw.Boundary()},
+	}
+	for _, header := range headers {
+		msg.WriteString(header[0] + ": " + header[1] + "\r\n")
+	}
+	msg.WriteString("\r\n")
+	msg.Write(body.Bytes())
+
+	return msg.Bytes(), nil
+}
+
+// NewEmailCode returns a random six digit code.
+func NewEmailCode() (string, error) {
+	n, err := rand.Int(rand.Reader, big.NewInt(1_000_000))
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("%06d", n.Int64()), nil
+}
+
+// VerifyEmailCode reports if code matches the pending verification in the user's settings. The settings have to
+// include this attempt, so codes stop working after MaxEmailCodeAttempts.
+func VerifyEmailCode(settings sqlgen.UserSetting, code string) bool {
+	if settings.PendingEmail == nil || settings.EmailCode == nil || settings.EmailCodeExpiresAt == nil {
+		return false
+	}
+
+	if settings.EmailCodeAttempts > MaxEmailCodeAttempts {
+		return false
+	}
+
+	if time.Now().After(*settings.EmailCodeExpiresAt) {
+		return false
+	}
+
+	return subtle.ConstantTimeCompare([]byte(*settings.EmailCode), []byte(strings.TrimSpace(code))) == 1
+}
+
+// the fields match cmd.ItemToEmbed
+var emailFuncs = map[string]any{
+	"price": func(item gw.Item) string { return fmt.Sprintf("$%.2f", item.CurrentPrice) },
+	"ends":  func(item gw.Item) string { return item.RelativeEndTime() },
+	"kind":  func(item gw.Item) string { return item.Kind() },
+	"url":   func(item gw.Item) string { return item.URL() },
+}
+
+var emailTextTemplate = texttemplate.Must(texttemplate.New("text").Funcs(emailFuncs).Parse(`{{ .Summary }}
+{{ range .Items }}
+{{ .Title }}
+{{ url . }}
+Current Price: {{ price . }}
+Ends: {{ ends . }} ({{ $.EndTime . }})
+Bids: {{ .NumBids }}
+Category: {{ .CategoryName }}
+Kind: {{ kind . }}
+{{ end }}{{ if .Overflow }}
+…and {{ .Overflow }} more
+{{ end }}`))
+
+var emailHTMLTemplate = htmltemplate.Must(htmltemplate.New("html").Funcs(emailFuncs).Parse(`<!DOCTYPE html>
+<html>
+<body style="font-family: sans-serif;">
+<h2>{{ .Summary }}</h2>
+{{ range .Items }}
+<div style="margin-bottom: 24px;">
+<h3><a href="{{ url . }}">{{ .Title }}</a></h3>
+<table cellpadding="4">
+<tr><th align="left">Current Price</th><td>{{ price . }}</td></tr>
+<tr><th align="left">Ends</th><td>{{ ends . }} ({{ $.EndTime . }})</td></tr>
+<tr><th align="left">Bids</th><td>{{ .NumBids }}</td></tr>
+<tr><th align="left">Category</th><td>{{ .CategoryName }}</td></tr>
+<tr><th align="left">Kind</th><td>{{ kind . }}</td></tr>
+</table>
+{{ if .ImageURL }}<a href="{{ url . }}"><img src="{{ .ImageURL }}" alt="{{ .Title }}" style="max-width: 400px;"></a>{{ end }}
+</div>
+{{ end }}
+{{ if .Overflow }}<p>…and {{ .Overflow }} more</p>{{ end }}
+</body>
+</html>
+`))
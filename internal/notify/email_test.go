@@ -0,0 +1,251 @@
+package notify
+
+import (
+	"bufio"
+	"context"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net"
+	"net/mail"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/pressly/goose/v3"
+	"github.com/robherley/gw-bot/internal/db"
+	"github.com/robherley/gw-bot/internal/db/sqlgen"
+	"github.com/robherley/gw-bot/internal/gw"
+)
+
+// smtpMessage is an email received by smtpSink.
+type smtpMessage struct {
+	from string
+	to   []string
+	data string
+}
+
+// smtpSink is a minimal SMTP server that accepts every message, without TLS or auth.
+func smtpSink(t *testing.T) (string, int, <-chan smtpMessage) {
+	t.Helper()
+
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { ln.Close() })
+
+	messages := make(chan smtpMessage, 1)
+	go func() {
+		conn, err := ln.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+
+		r := bufio.NewReader(conn)
+		reply := func(line string) {
+			_, _ = io.WriteString(conn, line+"\r\n")
+		}
+
+		var msg smtpMessage
+		reply("220 localhost ESMTP")
+		for {
+			line, err := r.ReadString('\n')
+			if err != nil {
+				return
+			}
+			line = strings.TrimRight(line, "\r\n")
+
+			switch cmd := strings.ToUpper(line); {
+			case strings.HasPrefix(cmd, "EHLO"), strings.HasPrefix(cmd, "HELO"):
+				reply("250 localhost")
+			case strings.HasPrefix(cmd, "MAIL FROM:"):
+				msg.from = strings.Trim(line[len("MAIL FROM:"):], "<>")
+				reply("250 OK")
+			case strings.HasPrefix(cmd, "RCPT TO:"):
+				msg.to = append(msg.to, strings.Trim(line[len("RCPT TO:"):], "<>"))
+				reply("250 OK")
+			case cmd == "DATA":
+				reply("354 End data with <CR><LF>.<CR><LF>")
+
+				data := strings.Builder{}
+				for {
+					line, err := r.ReadString('\n')
+					if err != nil {
+						return
+					}
+					if line == ".\r\n" {
+						break
+					}
+					data.WriteString(strings.TrimPrefix(line, "."))
+				}
+
+				msg.data = data.String()
+				reply("250 OK")
+			case cmd == "QUIT":
+				reply("221 Bye")
+				messages <- msg
+				return
+			default:
+				reply("502 Command not implemented")
+			}
+		}
+	}()
+
+	addr := ln.Addr().(*net.TCPAddr)
+	return addr.IP.String(), addr.Port, messages
+}
+
+func TestEmailNotify(t *testing.T) {
+	ctx := context.Background()
+
+	d, err := db.NewSQLite(filepath.Join(t.TempDir(), "email.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { d.Close() })
+
+	goose.SetLogger(goose.NopLogger())
+	if err := d.Migrate(ctx, os.DirFS("../..")); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := d.(*db.SQLite).ExecContext(ctx, "INSERT INTO user_settings (user_id, email) VALUES ('user', 'someone@example.com')"); err != nil {
+		t.Fatal(err)
+	}
+
+	host, port, messages := smtpSink(t)
+	email := NewEmail(d, SMTPConfig{Host: host, Port: port, From: "gw-bot@example.com"})
+
+	err = email.Notify(ctx, Notification{
+		Kind:         KindNewItems,
+		Subscription: sqlgen.Subscription{UserID: "user", Term: "pyrex bowl"},
+		Items: []gw.Item{{
+			ItemID:       1,
+			Title:        "Vintage Pyrex Bowl",
+			CurrentPrice: 12.5,
+			EndTime:      time.Now().Add(time.Hour),
+		}},
+	})
+	if err != nil {
+		t.Fatalf("Notify() error = %v", err)
+	}
+
+	var got smtpMessage
+	select {
+	case got = <-messages:
+	case <-time.After(5 * time.Second):
+		t.Fatal("no email was received")
+	}
+
+	if got.from != "gw-bot@example.com" {
+		t.Errorf("MAIL FROM = %q, want %q", got.from, "gw-bot@example.com")
+	}
+
+	if len(got.to) != 1 || got.to[0] != "someone@example.com" {
+		t.Errorf("RCPT TO = %v, want [someone@example.com]", got.to)
+	}
+
+	msg, err := mail.ReadMessage(strings.NewReader(got.data))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if to := msg.Header.Get("To"); to != "someone@example.com" {
+		t.Errorf("To = %q, want %q", to, "someone@example.com")
+	}
+
+	subject, err := new(mime.WordDecoder).DecodeHeader(msg.Header.Get("Subject"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if want := `🔔 New items for "pyrex bowl"!`; subject != want {
+		t.Errorf("Subject = %q, want %q", subject, want)
+	}
+
+	mediaType, params, err := mime.ParseMediaType(msg.Header.Get("Content-Type"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if mediaType != "multipart/alternative" {
+		t.Fatalf("Content-Type = %q, want multipart/alternative", mediaType)
+	}
+
+	// parts are decoded from quoted-printable by the reader
+	parts := map[string]string{}
+	mr := multipart.NewReader(msg.Body, params["boundary"])
+	for {
+		part, err := mr.NextPart()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		body, err := io.ReadAll(part)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		contentType, _, _ := mime.ParseMediaType(part.Header.Get("Content-Type"))
+		parts[contentType] = string(body)
+	}
+
+	text := parts["text/plain"]
+	for _, want := range []string{"Vintage Pyrex Bowl", "Current Price: $12.50", gw.ItemURL(1)} {
+		if !strings.Contains(text, want) {
+			t.Errorf("text part doesn't contain %q:\n%s", want, text)
+		}
+	}
+
+	html := parts["text/html"]
+	for _, want := range []string{`<a href="` + gw.ItemURL(1) + `">Vintage Pyrex Bowl</a>`, "<td>$12.50</td>"} {
+		if !strings.Contains(html, want) {
+			t.Errorf("html part doesn't contain %q:\n%s", want, html)
+		}
+	}
+}
+
+func TestVerifyEmailCode(t *testing.T) {
+	email := "someone@example.com"
+	code := "123456"
+	expiresAt := time.Now().Add(EmailCodeTTL)
+	expired := time.Now().Add(-time.Minute)
+
+	tests := []struct {
+		name      string
+		code      string
+		expiresAt *time.Time
+		attempts  int64
+		want      bool
+	}{
+		{name: "matches", code: "123456", expiresAt: &expiresAt, attempts: 1, want: true},
+		{name: "trims space", code: " 123456 ", expiresAt: &expiresAt, attempts: 1, want: true},
+		{name: "wrong code", code: "654321", expiresAt: &expiresAt, attempts: 1, want: false},
+		{name: "expired", code: "123456", expiresAt: &expired, attempts: 1, want: false},
+		{name: "no pending code", code: "123456", expiresAt: nil, attempts: 1, want: false},
+		{name: "last attempt", code: "123456", expiresAt: &expiresAt, attempts: MaxEmailCodeAttempts, want: true},
+		{name: "too many attempts", code: "123456", expiresAt: &expiresAt, attempts: MaxEmailCodeAttempts + 1, want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			settings := sqlgen.UserSetting{
+				PendingEmail:       &email,
+				EmailCode:          &code,
+				EmailCodeExpiresAt: tt.expiresAt,
+				EmailCodeAttempts:  tt.attempts,
+			}
+
+			if got := VerifyEmailCode(settings, tt.code); got != tt.want {
+				t.Errorf("VerifyEmailCode(%q) = %v, want %v", tt.code, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,122 @@
+// Package notify sends alerts about subscription items, independent of where they end up.
+package notify
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/robherley/gw-bot/internal/db/sqlgen"
+	"github.com/robherley/gw-bot/internal/gw"
+)
+
+type Kind string
+
+const (
+	KindNewItems   Kind = "new_items"
+	KindEndingSoon Kind = "ending_soon"
+	KindDigest     Kind = "digest"
+	// KindWatched is for watched items ending soon, which aren't part of a subscription.
+	KindWatched Kind = "watched"
+	// KindItemUpdate is for a single item that changed since it was found, see ItemChanges.
+	KindItemUpdate Kind = "item_update"
+	// KindSold is the final price of a single item after it ended.
+	KindSold Kind = "sold"
+)
+
+type Notification struct {
+	Kind Kind
+	// Subscription only has the UserID for watched items.
+	Subscription sqlgen.Subscription
+	Items        []gw.Item
+	// Overflow is the number of items left out of a digest.
+	Overflow int
+	// Location is the user's timezone, for showing times.
+	Location *time.Location
+	// Changes are what changed about the item of an update.
+	Changes []string
+}
+
+// Summary is a one line description of the notification, like a message or subject line.
+func (n Notification) Summary() string {
+	switch n.Kind {
+	case KindNewItems:
+		return fmt.Sprintf("🔔 New items for %q!", n.Subscription.Term)
+	case KindEndingSoon:
+		return fmt.Sprintf("⏰ Items ending soon for %q!", n.Subscription.Term)
+	case KindDigest:
+		return fmt.Sprintf("📬 %d new item(s) for %q!", len(n.Items)+n.Overflow, n.Subscription.Term)
+	case KindWatched:
+		return "👀 Watched items ending soon!"
+	case KindItemUpdate:
+		return "📈 " + strings.Join(n.Changes, ", ")
+	case KindSold:
+		if len(n.Items) == 0 {
+			return "🔨 Auction ended"
+		}
+		return SoldSummary(n.Items[0])
+	default:
+		return fmt.Sprintf("Alert for %q", n.Subscription.Term)
+	}
+}
+
+// SoldSummary describes the final price of an item that ended, like "🔨 Sold for $12.50 with 3 bids".
+func SoldSummary(item gw.Item) string {
+	if item.NumBids == 0 {
+		return fmt.Sprintf("🔨 Ended without any bids at $%.2f", item.CurrentPrice)
+	}
+	return fmt.Sprintf("🔨 Sold for $%.2f with %d bid(s)", item.CurrentPrice, item.NumBids)
+}
+
+// EndTime formats when the item ends in the user's timezone.
+func (n Notification) EndTime(item gw.Item) string {
+	loc := n.Location
+	if loc == nil {
+		loc = time.UTC
+	}
+	return item.EndTime.In(loc).Format("Mon Jan 2 3:04 PM MST")
+}
+
+type Notifier interface {
+	Notify(ctx context.Context, n Notification) error
+}
+
+// Sink is a notifier the outbox sends to on its own, so one failing doesn't send the notification to the rest again.
+type Sink struct {
+	// Name identifies the sink in the outbox, it shouldn't change.
+	Name     string
+	Notifier Notifier
+}
+
+// Targeted is a notifier that sends each notification to several targets, like a user's webhooks. The outbox keeps a
+// message per target, so one failing doesn't send the notification to the rest again.
+type Targeted interface {
+	Notifier
+	// Targets returns the targets of the notification, found with q so they're enqueued in the same transaction.
+	Targets(ctx context.Context, q sqlgen.Querier, n Notification) ([]string, error)
+	// NotifyTarget sends the notification to one target. deliveryID is the same every time the same message is
+	// retried, so receivers can tell they already got it.
+	NotifyTarget(ctx context.Context, n Notification, target, deliveryID string) error
+}
+
+// Retryable reports if sending a notification that failed with err might succeed later. Errors are retryable unless
+// they say otherwise with a Retryable method, like WebhookStatusError, or are ErrWebhookAddress. Joined errors are
+// retryable if any of them are.
+func Retryable(err error) bool {
+	switch err := err.(type) {
+	case interface{ Retryable() bool }:
+		return err.Retryable()
+	case interface{ Unwrap() []error }:
+		for _, err := range err.Unwrap() {
+			if Retryable(err) {
+				return true
+			}
+		}
+		return false
+	case interface{ Unwrap() error }:
+		return Retryable(err.Unwrap())
+	default:
+		return err != ErrWebhookAddress
+	}
+}
@@ -0,0 +1,69 @@
+package notify
+
+import (
+	"encoding/json"
+	"log/slog"
+	"time"
+
+	"github.com/robherley/gw-bot/internal/db/sqlgen"
+	"github.com/robherley/gw-bot/internal/gw"
+)
+
+// outboxNotification is a notification as it's stored in the outbox, locations are stored by name.
+type outboxNotification struct {
+	Kind         Kind                `json:"kind"`
+	Subscription sqlgen.Subscription `json:"subscription"`
+	Items        []gw.Item           `json:"items"`
+	Overflow     int                 `json:"overflow,omitempty"`
+	Timezone     string              `json:"timezone,omitempty"`
+	Changes      []string            `json:"changes,omitempty"`
+}
+
+// EncodeNotification encodes a notification to be sent later, see DecodeNotification.
+func EncodeNotification(n Notification) (string, error) {
+	stored := outboxNotification{
+		Kind:         n.Kind,
+		Subscription: n.Subscription,
+		Items:        n.Items,
+		Overflow:     n.Overflow,
+		Changes:      n.Changes,
+	}
+
+	if n.Location != nil {
+		stored.Timezone = n.Location.String()
+	}
+
+	b, err := json.Marshal(stored)
+	if err != nil {
+		return "", err
+	}
+
+	return string(b), nil
+}
+
+func DecodeNotification(s string) (Notification, error) {
+	var stored outboxNotification
+	if err := json.Unmarshal([]byte(s), &stored); err != nil {
+		return Notification{}, err
+	}
+
+	n := Notification{
+		Kind:         stored.Kind,
+		Subscription: stored.Subscription,
+		Items:        stored.Items,
+		Overflow:     stored.Overflow,
+		Changes:      stored.Changes,
+		Location:     time.UTC,
+	}
+
+	if stored.Timezone != "" {
+		loc, err := time.LoadLocation(stored.Timezone)
+		if err != nil {
+			slog.Warn("failed to load notification timezone", "timezone", stored.Timezone, "error", err)
+		} else {
+			n.Location = loc
+		}
+	}
+
+	return n, nil
+}
@@ -0,0 +1,67 @@
+package notify
+
+import (
+	"log/slog"
+	"time"
+
+	"github.com/robherley/gw-bot/internal/db/sqlgen"
+)
+
+// QuietHours is a daily window in which new items are held back. The window wraps around midnight when Start is
+// after End, like 22 to 7.
+type QuietHours struct {
+	Start    int
+	End      int
+	Location *time.Location
+}
+
+// QuietHoursFor returns the quiet hours in the user's settings, or nil if there aren't any.
+func QuietHoursFor(settings *sqlgen.UserSetting) *QuietHours {
+	if settings == nil || settings.QuietStart == nil || settings.QuietEnd == nil || *settings.QuietStart == *settings.QuietEnd {
+		return nil
+	}
+
+	return &QuietHours{
+		Start:    int(*settings.QuietStart),
+		End:      int(*settings.QuietEnd),
+		Location: LocationFor(settings),
+	}
+}
+
+// Contains reports if t is within the quiet hours.
+func (q *QuietHours) Contains(t time.Time) bool {
+	if q == nil {
+		return false
+	}
+
+	hour := t.In(q.Location).Hour()
+	if q.Start < q.End {
+		return hour >= q.Start && hour < q.End
+	}
+	return hour >= q.Start || hour < q.End
+}
+
+// EndAfter returns the first end of the quiet hours after t.
+func (q *QuietHours) EndAfter(t time.Time) time.Time {
+	local := t.In(q.Location)
+	end := time.Date(local.Year(), local.Month(), local.Day(), q.End, 0, 0, 0, q.Location)
+	if !end.After(local) {
+		end = end.AddDate(0, 0, 1)
+	}
+	return end
+}
+
+// LocationFor returns the user's timezone, which is UTC if they never set one.
+func LocationFor(settings *sqlgen.UserSetting) *time.Location {
+	if settings == nil || settings.Timezone == "" {
+		return time.UTC
+	}
+
+	loc, err := time.LoadLocation(settings.Timezone)
+	if err != nil {
+		slog.Warn("failed to load user timezone", "user_id", settings.UserID, "timezone", settings.Timezone, "error", err)
+		return time.UTC
+	}
+
+	return loc
+}
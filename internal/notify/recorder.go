@@ -0,0 +1,49 @@
+package notify
+
+import (
+	"context"
+	"sync"
+)
+
+// Recorder keeps every notification it receives, for inspecting what would have been sent.
+type Recorder struct {
+	mu            sync.Mutex
+	notifications []Notification
+	err           error
+}
+
+func NewRecorder() *Recorder {
+	return &Recorder{}
+}
+
+func (r *Recorder) Notify(ctx context.Context, n Notification) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.notifications = append(r.notifications, n)
+	return r.err
+}
+
+// Notifications returns a copy of the recorded notifications, oldest first.
+func (r *Recorder) Notifications() []Notification {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return append([]Notification(nil), r.notifications...)
+}
+
+// FailWith makes every following Notify call return err, after recording the notification.
+func (r *Recorder) FailWith(err error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.err = err
+}
+
+func (r *Recorder) Reset() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.notifications = nil
+	r.err = nil
+}
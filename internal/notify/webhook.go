@@ -0,0 +1,287 @@
+package notify
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net"
+	"net/http"
+	"net/netip"
+	"syscall"
+	"time"
+
+	"github.com/robherley/gw-bot/internal/db"
+	"github.com/robherley/gw-bot/internal/db/sqlgen"
+	"github.com/robherley/gw-bot/internal/gw"
+	"github.com/robherley/gw-bot/internal/meta"
+)
+
+const (
+	// WebhookPayloadVersion is bumped whenever the payload changes in a way that could break receivers.
+	WebhookPayloadVersion = 1
+
+	WebhookSignatureHeader = "X-GW-Bot-Signature"
+	WebhookEventHeader     = "X-GW-Bot-Event"
+	WebhookDeliveryHeader  = "X-GW-Bot-Delivery"
+
+	WebhookTimeout = 10 * time.Second
+)
+
+// ErrWebhookAddress is returned for webhooks on loopback, private, link-local or unspecified addresses, so users
+// can't reach the bot's own network.
+var ErrWebhookAddress = errors.New("webhook address is not allowed")
+
+// KindTest is only sent to webhooks, to check that a receiver is set up correctly.
+const KindTest Kind = "test"
+
+type WebhookPayload struct {
+	Version      int                 `json:"version"`
+	Kind         Kind                `json:"kind"`
+	SentAt       time.Time           `json:"sentAt"`
+	Subscription WebhookSubscription `json:"subscription"`
+	Items        []WebhookItem       `json:"items"`
+	Overflow     int                 `json:"overflow"`
+	Changes      []string            `json:"changes,omitempty"`
+}
+
+type WebhookSubscription struct {
+	ID       string `json:"id"`
+	Term     string `json:"term"`
+	MinPrice *int64 `json:"minPrice"`
+	MaxPrice *int64 `json:"maxPrice"`
+}
+
+type WebhookItem struct {
+	gw.Item
+	URL string `json:"url"`
+}
+
+func NewWebhookPayload(n Notification) WebhookPayload {
+	items := make([]WebhookItem, 0, len(n.Items))
+	for _, item := range n.Items {
+		items = append(items, WebhookItem{item, item.URL()})
+	}
+
+	return WebhookPayload{
+		Version: WebhookPayloadVersion,
+		Kind:    n.Kind,
+		SentAt:  time.Now().UTC(),
+		Subscription: WebhookSubscription{
+			ID:       n.Subscription.ID,
+			Term:     n.Subscription.Term,
+			MinPrice: n.Subscription.MinPrice,
+			MaxPrice: n.Subscription.MaxPrice,
+		},
+		Items:    items,
+		Overflow: n.Overflow,
+		Changes:  n.Changes,
+	}
+}
+
+// Webhook posts notifications as JSON to the webhooks configured for the subscription and its user.
+type Webhook struct {
+	db     db.DB
+	client *http.Client
+}
+
+func NewWebhook(db db.DB) *Webhook {
+	dialer := &net.Dialer{
+		Timeout: WebhookTimeout,
+		// checked on every connection, after DNS resolution, so redirects and rebinding are covered too
+		Control: webhookControl,
+	}
+
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	// a proxy would be dialed instead of the webhook, skipping the check
+	transport.Proxy = nil
+	transport.DialContext = dialer.DialContext
+
+	return &Webhook{
+		db: db,
+		client: &http.Client{
+			Timeout:   WebhookTimeout,
+			Transport: transport,
+		},
+	}
+}
+
+func (w *Webhook) Notify(ctx context.Context, n Notification) error {
+	hooks, err := w.db.FindSubscriptionWebhooks(ctx, sqlgen.FindSubscriptionWebhooksParams{
+		UserID:         n.Subscription.UserID,
+		SubscriptionID: n.Subscription.ID,
+	})
+	if err != nil {
+		return err
+	}
+
+	if len(hooks) == 0 {
+		return nil
+	}
+
+	payload := NewWebhookPayload(n)
+
+	errs := make([]error, 0)
+	for _, hook := range hooks {
+		if err := w.Deliver(ctx, hook, db.NewID(), payload); err != nil {
+			errs = append(errs, fmt.Errorf("webhook %s: %w", hook.ID, err))
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// Targets returns the IDs of the webhooks configured for the subscription and its user.
+func (w *Webhook) Targets(ctx context.Context, q sqlgen.Querier, n Notification) ([]string, error) {
+	hooks, err := q.FindSubscriptionWebhooks(ctx, sqlgen.FindSubscriptionWebhooksParams{
+		UserID:         n.Subscription.UserID,
+		SubscriptionID: n.Subscription.ID,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	ids := make([]string, 0, len(hooks))
+	for _, hook := range hooks {
+		ids = append(ids, hook.ID)
+	}
+
+	return ids, nil
+}
+
+// NotifyTarget delivers the notification to the webhook with the ID target, if it wasn't removed since.
+func (w *Webhook) NotifyTarget(ctx context.Context, n Notification, target, deliveryID string) error {
+	hook, err := w.db.FindWebhook(ctx, target)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	return w.Deliver(ctx, hook, deliveryID, NewWebhookPayload(n))
+}
+
+// Deliver sends the payload to the webhook once, with deliveryID in the WebhookDeliveryHeader. Failed deliveries are
+// retried by the outbox with the same deliveryID, see WebhookStatusError.Retryable.
+func (w *Webhook) Deliver(ctx context.Context, hook sqlgen.Webhook, deliveryID string, payload WebhookPayload) error {
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+
+	if err := w.post(ctx, hook, payload.Kind, deliveryID, body); err != nil {
+		return err
+	}
+
+	slog.Info("delivered webhook", "webhook_id", hook.ID, "user_id", hook.UserID, "kind", payload.Kind, "delivery_id", deliveryID)
+	return nil
+}
+
+func (w *Webhook) post(ctx context.Context, hook sqlgen.Webhook, kind Kind, deliveryID string, body []byte) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, hook.Url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "gw-bot/"+meta.Version)
+	req.Header.Set(WebhookSignatureHeader, Sign(hook.Secret, body))
+	req.Header.Set(WebhookEventHeader, string(kind))
+	req.Header.Set(WebhookDeliveryHeader, deliveryID)
+
+	resp, err := w.client.Do(req)
+	if err != nil {
+		if errors.Is(err, ErrWebhookAddress) {
+			return ErrWebhookAddress
+		}
+		return err
+	}
+	defer resp.Body.Close()
+
+	// drain so the connection can be reused
+	_, _ = io.Copy(io.Discard, resp.Body)
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return &WebhookStatusError{resp.StatusCode}
+	}
+
+	return nil
+}
+
+type WebhookStatusError struct {
+	StatusCode int
+}
+
+func (e *WebhookStatusError) Error() string {
+	return fmt.Sprintf("unexpected status code: %d", e.StatusCode)
+}
+
+// Retryable reports if the receiver might accept the same payload later.
+func (e *WebhookStatusError) Retryable() bool {
+	return e.StatusCode == http.StatusTooManyRequests || e.StatusCode >= 500
+}
+
+// CheckWebhookHost resolves the host of a webhook URL and returns ErrWebhookAddress if any of its addresses aren't
+// allowed. Deliveries are checked again when connecting, since the host can resolve differently later.
+func CheckWebhookHost(ctx context.Context, host string) error {
+	addrs, err := net.DefaultResolver.LookupNetIP(ctx, "ip", host)
+	if err != nil {
+		return err
+	}
+
+	for _, addr := range addrs {
+		if !webhookAddrAllowed(addr) {
+			return ErrWebhookAddress
+		}
+	}
+
+	return nil
+}
+
+func webhookControl(network, address string, _ syscall.RawConn) error {
+	addrPort, err := netip.ParseAddrPort(address)
+	if err != nil {
+		return err
+	}
+
+	if !webhookAddrAllowed(addrPort.Addr()) {
+		return ErrWebhookAddress
+	}
+
+	return nil
+}
+
+func webhookAddrAllowed(addr netip.Addr) bool {
+	addr = addr.Unmap()
+	return addr.IsGlobalUnicast() && !addr.IsPrivate() && !addr.IsLoopback() && !addr.IsLinkLocalUnicast()
+}
+
+// Sign returns the signature header value for the body, like "sha256=<hex hmac>".
+func Sign(secret string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// VerifySignature reports if signature is a valid signature header value of the body, for receivers to check
+// that payloads came from the bot.
+func VerifySignature(secret string, body []byte, signature string) bool {
+	return hmac.Equal([]byte(Sign(secret, body)), []byte(signature))
+}
+
+func NewWebhookSecret() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
@@ -0,0 +1,101 @@
+package notify
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+
+	"github.com/robherley/gw-bot/internal/db/sqlgen"
+)
+
+func TestWebhookDeliver(t *testing.T) {
+	tests := []struct {
+		name      string
+		status    int
+		wantErr   bool
+		retryable bool
+	}{
+		{name: "ok", status: http.StatusNoContent},
+		{name: "server error", status: http.StatusBadGateway, wantErr: true, retryable: true},
+		{name: "rate limited", status: http.StatusTooManyRequests, wantErr: true, retryable: true},
+		{name: "client error", status: http.StatusNotFound, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var requests atomic.Int32
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				requests.Add(1)
+
+				body, err := io.ReadAll(r.Body)
+				if err != nil {
+					t.Errorf("failed to read body: %v", err)
+				}
+
+				if !VerifySignature("secret", body, r.Header.Get(WebhookSignatureHeader)) {
+					t.Errorf("invalid signature %q", r.Header.Get(WebhookSignatureHeader))
+				}
+
+				if got := r.Header.Get(WebhookEventHeader); got != string(KindTest) {
+					t.Errorf("event header = %q, want %q", got, KindTest)
+				}
+
+				if got := r.Header.Get(WebhookDeliveryHeader); got != "delivery" {
+					t.Errorf("delivery header = %q, want %q", got, "delivery")
+				}
+
+				w.WriteHeader(tt.status)
+			}))
+			defer srv.Close()
+
+			// the test server is on loopback, which NewWebhook's client refuses
+			w := &Webhook{client: srv.Client()}
+			hook := sqlgen.Webhook{ID: "hook", Url: srv.URL, Secret: "secret"}
+
+			err := w.Deliver(context.Background(), hook, "delivery", NewWebhookPayload(Notification{Kind: KindTest}))
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Deliver() error = %v, wantErr %v", err, tt.wantErr)
+			}
+
+			if err != nil {
+				var statusErr *WebhookStatusError
+				if !errors.As(err, &statusErr) {
+					t.Fatalf("Deliver() error = %v, want a WebhookStatusError", err)
+				}
+
+				if statusErr.Retryable() != tt.retryable {
+					t.Errorf("Retryable() = %v, want %v", statusErr.Retryable(), tt.retryable)
+				}
+			}
+
+			// retries are left to the outbox
+			if got := requests.Load(); got != 1 {
+				t.Errorf("got %d requests, want 1", got)
+			}
+		})
+	}
+}
+
+func TestWebhookDeliverPrivateAddress(t *testing.T) {
+	var requests atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+	}))
+	defer srv.Close()
+
+	w := NewWebhook(nil)
+	hook := sqlgen.Webhook{ID: "hook", Url: srv.URL, Secret: "secret"}
+
+	err := w.Deliver(context.Background(), hook, "delivery", NewWebhookPayload(Notification{Kind: KindTest}))
+	if !errors.Is(err, ErrWebhookAddress) {
+		t.Fatalf("Deliver() error = %v, want %v", err, ErrWebhookAddress)
+	}
+
+	if got := requests.Load(); got != 0 {
+		t.Errorf("got %d requests, want 0", got)
+	}
+}
@@ -0,0 +1,157 @@
+package query
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/robherley/gw-bot/internal/duration"
+	"github.com/robherley/gw-bot/internal/gw"
+)
+
+type Node interface {
+	Match(item gw.Item) bool
+	String() string
+}
+
+type Op string
+
+const (
+	OpLess         Op = "<"
+	OpLessEqual    Op = "<="
+	OpGreater      Op = ">"
+	OpGreaterEqual Op = ">="
+)
+
+func (op Op) compare(a, b float64) bool {
+	switch op {
+	case OpLess:
+		return a < b
+	case OpLessEqual:
+		return a <= b
+	case OpGreater:
+		return a > b
+	case OpGreaterEqual:
+		return a >= b
+	default:
+		return false
+	}
+}
+
+// Term is a word, or a phrase when quoted, that must be in the item title.
+type Term struct {
+	Text   string
+	Phrase bool
+}
+
+func (t *Term) Match(item gw.Item) bool {
+	return gw.ContainsWord(strings.ToLower(item.Title), t.Text)
+}
+
+func (t *Term) String() string {
+	if t.Phrase {
+		return `"` + t.Text + `"`
+	}
+	return t.Text
+}
+
+type And struct {
+	Nodes []Node
+}
+
+func (a *And) Match(item gw.Item) bool {
+	for _, node := range a.Nodes {
+		if !node.Match(item) {
+			return false
+		}
+	}
+	return true
+}
+
+func (a *And) String() string {
+	return join(a.Nodes, " ")
+}
+
+type Or struct {
+	Nodes []Node
+}
+
+func (o *Or) Match(item gw.Item) bool {
+	for _, node := range o.Nodes {
+		if node.Match(item) {
+			return true
+		}
+	}
+	return false
+}
+
+func (o *Or) String() string {
+	return join(o.Nodes, " OR ")
+}
+
+type Not struct {
+	Node Node
+}
+
+func (n *Not) Match(item gw.Item) bool {
+	return !n.Node.Match(item)
+}
+
+func (n *Not) String() string {
+	// "--word" would read as a negated word starting with a dash
+	if _, ok := n.Node.(*Not); ok {
+		return "-(" + n.Node.String() + ")"
+	}
+	return "-" + group(n.Node)
+}
+
+// Price compares the current price of an item.
+type Price struct {
+	Op    Op
+	Value float64
+}
+
+func (p *Price) Match(item gw.Item) bool {
+	return p.Op.compare(item.CurrentPrice, p.Value)
+}
+
+func (p *Price) String() string {
+	return fmt.Sprintf("price:%s%s", p.Op, strconv.FormatFloat(p.Value, 'f', -1, 64))
+}
+
+// Ends compares how long until an item ends.
+type Ends struct {
+	Op       Op
+	Duration time.Duration
+}
+
+func (e *Ends) Match(item gw.Item) bool {
+	return e.Op.compare(float64(time.Until(item.EndTime)), float64(e.Duration))
+}
+
+func (e *Ends) String() string {
+	return fmt.Sprintf("ends:%s%s", e.Op, duration.Format(e.Duration))
+}
+
+func join(nodes []Node, sep string) string {
+	parts := make([]string, 0, len(nodes))
+	for _, node := range nodes {
+		parts = append(parts, group(node))
+	}
+	return strings.Join(parts, sep)
+}
+
+func group(node Node) string {
+	switch node := node.(type) {
+	case *And:
+		if len(node.Nodes) > 1 {
+			return "(" + node.String() + ")"
+		}
+	case *Or:
+		if len(node.Nodes) > 1 {
+			return "(" + node.String() + ")"
+		}
+	}
+	return node.String()
+}
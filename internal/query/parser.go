@@ -0,0 +1,296 @@
+package query
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/robherley/gw-bot/internal/duration"
+)
+
+type tokenKind int
+
+const (
+	tokenWord tokenKind = iota
+	tokenPhrase
+	tokenOr
+	tokenNot
+	tokenOpen
+	tokenClose
+)
+
+type token struct {
+	kind tokenKind
+	text string
+}
+
+func tokenize(s string) ([]token, error) {
+	tokens := make([]token, 0)
+	runes := []rune(s)
+
+	for i := 0; i < len(runes); {
+		r := runes[i]
+		switch {
+		case unicode.IsSpace(r):
+			i++
+		case r == '(':
+			tokens = append(tokens, token{kind: tokenOpen})
+			i++
+		case r == ')':
+			tokens = append(tokens, token{kind: tokenClose})
+			i++
+		case r == '-' && i+1 < len(runes) && !unicode.IsSpace(runes[i+1]) && (i == 0 || isBoundary(runes[i-1])):
+			tokens = append(tokens, token{kind: tokenNot})
+			i++
+		case r == '"':
+			end := i + 1
+			for end < len(runes) && runes[end] != '"' {
+				end++
+			}
+			if end == len(runes) {
+				return nil, fmt.Errorf("missing closing quote")
+			}
+
+			phrase := strings.Join(strings.Fields(string(runes[i+1:end])), " ")
+			if phrase == "" {
+				return nil, fmt.Errorf("empty quotes")
+			}
+
+			tokens = append(tokens, token{kind: tokenPhrase, text: phrase})
+			i = end + 1
+		default:
+			end := i
+			for end < len(runes) && !unicode.IsSpace(runes[end]) && runes[end] != '(' && runes[end] != ')' && runes[end] != '"' {
+				end++
+			}
+
+			word := string(runes[i:end])
+			if word == "OR" {
+				tokens = append(tokens, token{kind: tokenOr})
+			} else {
+				tokens = append(tokens, token{kind: tokenWord, text: word})
+			}
+			i = end
+		}
+	}
+
+	return tokens, nil
+}
+
+func isBoundary(r rune) bool {
+	return unicode.IsSpace(r) || r == '(' || r == '"'
+}
+
+// parser is a recursive descent parser for:
+//
+//	or    = and { "OR" and }
+//	and   = unary { unary }
+//	unary = "-" unary | "(" or ")" | word | phrase | qualifier
+type parser struct {
+	tokens []token
+	pos    int
+}
+
+func (p *parser) parse() (Node, error) {
+	if len(p.tokens) == 0 {
+		return nil, fmt.Errorf("empty query")
+	}
+
+	node, err := p.or()
+	if err != nil {
+		return nil, err
+	}
+
+	if p.pos < len(p.tokens) {
+		return nil, fmt.Errorf("unexpected %s", p.tokens[p.pos].describe())
+	}
+
+	return node, nil
+}
+
+func (p *parser) peek() (token, bool) {
+	if p.pos >= len(p.tokens) {
+		return token{}, false
+	}
+	return p.tokens[p.pos], true
+}
+
+func (p *parser) or() (Node, error) {
+	nodes := make([]Node, 0, 1)
+	for {
+		node, err := p.and()
+		if err != nil {
+			return nil, err
+		}
+		nodes = append(nodes, node)
+
+		tok, ok := p.peek()
+		if !ok || tok.kind != tokenOr {
+			break
+		}
+		p.pos++
+	}
+
+	if len(nodes) == 1 {
+		return nodes[0], nil
+	}
+	return &Or{Nodes: nodes}, nil
+}
+
+func (p *parser) and() (Node, error) {
+	nodes := make([]Node, 0, 1)
+	for {
+		tok, ok := p.peek()
+		if !ok || tok.kind == tokenOr || tok.kind == tokenClose {
+			break
+		}
+
+		node, err := p.unary()
+		if err != nil {
+			return nil, err
+		}
+
+		// flatten so price ranges and the like read as one list of conditions
+		if and, ok := node.(*And); ok {
+			nodes = append(nodes, and.Nodes...)
+		} else {
+			nodes = append(nodes, node)
+		}
+	}
+
+	if len(nodes) == 0 {
+		if tok, ok := p.peek(); ok {
+			return nil, fmt.Errorf("unexpected %s", tok.describe())
+		}
+		return nil, fmt.Errorf("unexpected end of query")
+	}
+
+	if len(nodes) == 1 {
+		return nodes[0], nil
+	}
+	return &And{Nodes: nodes}, nil
+}
+
+func (p *parser) unary() (Node, error) {
+	tok, ok := p.peek()
+	if !ok {
+		return nil, fmt.Errorf("unexpected end of query")
+	}
+	p.pos++
+
+	switch tok.kind {
+	case tokenNot:
+		node, err := p.unary()
+		if err != nil {
+			return nil, err
+		}
+		return &Not{Node: node}, nil
+	case tokenOpen:
+		node, err := p.or()
+		if err != nil {
+			return nil, err
+		}
+
+		if tok, ok := p.peek(); !ok || tok.kind != tokenClose {
+			return nil, fmt.Errorf("missing closing parenthesis")
+		}
+		p.pos++
+
+		return node, nil
+	case tokenPhrase:
+		return &Term{Text: strings.ToLower(tok.text), Phrase: true}, nil
+	case tokenWord:
+		if key, value, ok := strings.Cut(tok.text, ":"); ok {
+			switch strings.ToLower(key) {
+			case "price":
+				return parsePrice(value)
+			case "ends":
+				return parseEnds(value)
+			}
+		}
+		return &Term{Text: strings.ToLower(tok.text)}, nil
+	default:
+		return nil, fmt.Errorf("unexpected %s", tok.describe())
+	}
+}
+
+func (t token) describe() string {
+	switch t.kind {
+	case tokenOr:
+		return "OR"
+	case tokenNot:
+		return "-"
+	case tokenOpen:
+		return `"("`
+	case tokenClose:
+		return `")"`
+	default:
+		return strconv.Quote(t.text)
+	}
+}
+
+func parseOp(value string) (Op, string) {
+	for _, op := range []Op{OpLessEqual, OpGreaterEqual, OpLess, OpGreater} {
+		if rest, ok := strings.CutPrefix(value, string(op)); ok {
+			return op, rest
+		}
+	}
+	return "", value
+}
+
+func parseAmount(value string) (float64, error) {
+	amount, err := strconv.ParseFloat(strings.TrimPrefix(value, "$"), 64)
+	if err != nil || amount < 0 {
+		return 0, fmt.Errorf("invalid price %q", value)
+	}
+	return amount, nil
+}
+
+func parsePrice(value string) (Node, error) {
+	op, rest := parseOp(value)
+	if op != "" {
+		amount, err := parseAmount(rest)
+		if err != nil {
+			return nil, err
+		}
+		return &Price{Op: op, Value: amount}, nil
+	}
+
+	if low, high, ok := strings.Cut(value, "-"); ok {
+		min, err := parseAmount(low)
+		if err != nil {
+			return nil, err
+		}
+
+		max, err := parseAmount(high)
+		if err != nil {
+			return nil, err
+		}
+
+		if min > max {
+			return nil, fmt.Errorf("invalid price range %q", value)
+		}
+
+		return &And{Nodes: []Node{
+			&Price{Op: OpGreaterEqual, Value: min},
+			&Price{Op: OpLessEqual, Value: max},
+		}}, nil
+	}
+
+	return nil, fmt.Errorf("price needs a comparison or range, like price:<40 or price:10-40")
+}
+
+func parseEnds(value string) (Node, error) {
+	op, rest := parseOp(value)
+	if op == "" {
+		return nil, fmt.Errorf("ends needs a comparison, like ends:<2h")
+	}
+
+	d, err := duration.Parse(rest)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Ends{Op: op, Duration: d}, nil
+}
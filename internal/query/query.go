@@ -0,0 +1,188 @@
+// Package query parses subscription terms like `"pyrex bowl" (blue OR green) -chipped price:<40 ends:<2h`.
+//
+// Words and quoted phrases outside of OR groups and negations are sent to ShopGoodwill as the search text,
+// everything else is matched locally against the items that come back.
+package query
+
+import (
+	"errors"
+	"math"
+	"slices"
+	"strings"
+
+	"github.com/robherley/gw-bot/internal/gw"
+)
+
+var ErrNoSearchText = errors.New("query needs at least one word to search for that isn't negated or part of an OR group")
+
+type Query struct {
+	root Node
+
+	// Text is the search text sent to ShopGoodwill.
+	Text string
+	// MinPrice and MaxPrice are the bounds sent to ShopGoodwill from top level price qualifiers.
+	MinPrice *int64
+	MaxPrice *int64
+}
+
+func Parse(s string) (*Query, error) {
+	tokens, err := tokenize(s)
+	if err != nil {
+		return nil, err
+	}
+
+	p := &parser{tokens: tokens}
+	root, err := p.parse()
+	if err != nil {
+		return nil, err
+	}
+
+	q := &Query{root: root}
+
+	words := make([]string, 0)
+	for _, node := range conjuncts(root) {
+		switch node := node.(type) {
+		case *Term:
+			words = append(words, node.Text)
+		case *Price:
+			q.tighten(node)
+		}
+	}
+
+	if len(words) == 0 {
+		return nil, ErrNoSearchText
+	}
+
+	q.Text = strings.Join(words, " ")
+	return q, nil
+}
+
+// Plain returns a query that searches for s as is, without any local matching.
+func Plain(s string) *Query {
+	return &Query{root: &And{}, Text: s}
+}
+
+func (q *Query) Match(item gw.Item) bool {
+	return q.root.Match(item)
+}
+
+// MayMatch reports if the item matches, or could match once it's closer to ending. Items are checked when they're
+// listed, long before they end, so `ends:` qualifiers are left for Match to check when the item is about to end.
+func (q *Query) MayMatch(item gw.Item) bool {
+	return eval(q.root, item) != no
+}
+
+// IsPlain reports if the query is only words and phrases, so everything is searched for by ShopGoodwill.
+func (q *Query) IsPlain() bool {
+	for _, node := range conjuncts(q.root) {
+		if _, ok := node.(*Term); !ok {
+			return false
+		}
+	}
+	return true
+}
+
+// HasEnds reports if the query checks the time left on items anywhere, see Ends.
+func (q *Query) HasEnds() bool {
+	return hasEnds(q.root)
+}
+
+// String returns the parsed form of the query in the same syntax, with groups in parentheses, like
+// `"pyrex bowl" (blue OR green) -chipped`. It parses back to the same query.
+func (q *Query) String() string {
+	return q.root.String()
+}
+
+func (q *Query) tighten(price *Price) {
+	switch price.Op {
+	case OpLess, OpLessEqual:
+		max := int64(math.Ceil(price.Value))
+		if q.MaxPrice == nil || max < *q.MaxPrice {
+			q.MaxPrice = &max
+		}
+	case OpGreater, OpGreaterEqual:
+		min := int64(math.Floor(price.Value))
+		if q.MinPrice == nil || min > *q.MinPrice {
+			q.MinPrice = &min
+		}
+	}
+}
+
+// conjuncts returns the nodes that must all match for the query to match.
+func conjuncts(node Node) []Node {
+	if and, ok := node.(*And); ok {
+		nodes := make([]Node, 0, len(and.Nodes))
+		for _, node := range and.Nodes {
+			nodes = append(nodes, conjuncts(node)...)
+		}
+		return nodes
+	}
+	return []Node{node}
+}
+
+// result is the outcome of matching a node when the time left on the item isn't known yet.
+type result int
+
+const (
+	no result = iota
+	yes
+	maybe
+)
+
+func eval(node Node, item gw.Item) result {
+	switch node := node.(type) {
+	case *Ends:
+		return maybe
+	case *And:
+		r := yes
+		for _, node := range node.Nodes {
+			switch eval(node, item) {
+			case no:
+				return no
+			case maybe:
+				r = maybe
+			}
+		}
+		return r
+	case *Or:
+		r := no
+		for _, node := range node.Nodes {
+			switch eval(node, item) {
+			case yes:
+				return yes
+			case maybe:
+				r = maybe
+			}
+		}
+		return r
+	case *Not:
+		switch eval(node.Node, item) {
+		case yes:
+			return no
+		case no:
+			return yes
+		default:
+			return maybe
+		}
+	default:
+		if node.Match(item) {
+			return yes
+		}
+		return no
+	}
+}
+
+func hasEnds(node Node) bool {
+	switch node := node.(type) {
+	case *Ends:
+		return true
+	case *And:
+		return slices.ContainsFunc(node.Nodes, hasEnds)
+	case *Or:
+		return slices.ContainsFunc(node.Nodes, hasEnds)
+	case *Not:
+		return hasEnds(node.Node)
+	default:
+		return false
+	}
+}
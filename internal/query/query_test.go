@@ -0,0 +1,334 @@
+package query
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/robherley/gw-bot/internal/gw"
+)
+
+func TestParseErrors(t *testing.T) {
+	tests := []struct {
+		in   string
+		want string
+	}{
+		{in: "", want: "empty query"},
+		{in: "   ", want: "empty query"},
+		{in: `"pyrex bowl`, want: "missing closing quote"},
+		{in: `pyrex ""`, want: "empty quotes"},
+		{in: "(pyrex bowl", want: "missing closing parenthesis"},
+		{in: "pyrex bowl)", want: `unexpected ")"`},
+		{in: "OR pyrex", want: "unexpected OR"},
+		{in: "pyrex OR", want: "unexpected end of query"},
+		{in: "pyrex () bowl", want: `unexpected ")"`},
+		{in: "pyrex price:abc", want: "price needs a comparison or range, like price:<40 or price:10-40"},
+		{in: "pyrex price:<abc", want: `invalid price "abc"`},
+		{in: "pyrex price:40-10", want: `invalid price range "40-10"`},
+		{in: "pyrex ends:2h", want: "ends needs a comparison, like ends:<2h"},
+		{in: "pyrex ends:<0m", want: "duration must be longer than zero"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.in, func(t *testing.T) {
+			_, err := Parse(tt.in)
+			if err == nil || err.Error() != tt.want {
+				t.Errorf("Parse(%q) error = %v, want %q", tt.in, err, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseNoSearchText(t *testing.T) {
+	for _, in := range []string{"-chipped", "(blue OR green)", "price:<40", "ends:<2h", "blue OR green"} {
+		t.Run(in, func(t *testing.T) {
+			if _, err := Parse(in); !errors.Is(err, ErrNoSearchText) {
+				t.Errorf("Parse(%q) error = %v, want %v", in, err, ErrNoSearchText)
+			}
+		})
+	}
+}
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		in   string
+		text string
+		want string
+	}{
+		{in: "pyrex bowl", text: "pyrex bowl", want: "pyrex bowl"},
+		{in: `"Pyrex  Bowl"`, text: "pyrex bowl", want: `"pyrex bowl"`},
+		// AND binds tighter than OR
+		{in: "a b OR c", text: "", want: "(a b) OR c"},
+		{in: "a OR b c", text: "", want: "a OR (b c)"},
+		{in: "a (b OR c)", text: "a", want: "a (b OR c)"},
+		{in: "a (b OR (c d))", text: "a", want: "a (b OR (c d))"},
+		// nested groups that must all match are flattened
+		{in: "a ((b c) d)", text: "a b c d", want: "a b c d"},
+		{in: "a -(b OR c)", text: "a", want: "a -(b OR c)"},
+		{in: "a -(b c)", text: "a", want: "a -(b c)"},
+		{in: "a -(-b)", text: "a", want: "a -(-b)"},
+		// dashes inside words aren't negations
+		{in: "t-shirt", text: "t-shirt", want: "t-shirt"},
+		{in: `"pyrex bowl" (blue OR green) -chipped price:<40 ends:<2h`, text: "pyrex bowl", want: `"pyrex bowl" (blue OR green) -chipped price:<40 ends:<2h`},
+		{in: "lamp price:10-40", text: "lamp", want: "lamp price:>=10 price:<=40"},
+		{in: "lamp PRICE:<$12.50", text: "lamp", want: "lamp price:<12.5"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.in, func(t *testing.T) {
+			q, err := Parse(tt.in)
+			if tt.text == "" {
+				if !errors.Is(err, ErrNoSearchText) {
+					t.Fatalf("Parse(%q) error = %v, want %v", tt.in, err, ErrNoSearchText)
+				}
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("Parse(%q) error = %v", tt.in, err)
+			}
+
+			if q.Text != tt.text {
+				t.Errorf("Parse(%q).Text = %q, want %q", tt.in, q.Text, tt.text)
+			}
+
+			if got := q.String(); got != tt.want {
+				t.Errorf("Parse(%q).String() = %q, want %q", tt.in, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParsePrecedence(t *testing.T) {
+	tests := []struct {
+		in    string
+		title string
+		want  bool
+	}{
+		{in: "a b OR c", title: "c", want: true},
+		{in: "a b OR c", title: "a b", want: true},
+		{in: "a b OR c", title: "a", want: false},
+		{in: "lamp -(brass OR chrome)", title: "brass lamp", want: false},
+		{in: "lamp -(brass OR chrome)", title: "glass lamp", want: true},
+		{in: "lamp -(brass chrome)", title: "brass lamp", want: true},
+		{in: "lamp -(brass chrome)", title: "brass chrome lamp", want: false},
+		{in: "lamp (brass OR (glass shade))", title: "glass lamp", want: false},
+		{in: "lamp (brass OR (glass shade))", title: "glass lamp shade", want: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.in+"/"+tt.title, func(t *testing.T) {
+			q := parse(t, tt.in)
+			if got := q.Match(gw.Item{Title: tt.title}); got != tt.want {
+				t.Errorf("Match(%q) = %v, want %v", tt.title, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestTermMatch(t *testing.T) {
+	tests := []struct {
+		term  Term
+		title string
+		want  bool
+	}{
+		{term: Term{Text: "lamp"}, title: "Brass Lamp", want: true},
+		{term: Term{Text: "lamp"}, title: "Lamps, set of 2", want: true},
+		{term: Term{Text: "lamp"}, title: "(lamp) shade", want: true},
+		{term: Term{Text: "lamp"}, title: "Desk-lamp", want: true},
+		{term: Term{Text: "lamp"}, title: "Bench clamp", want: false},
+		{term: Term{Text: "lamp"}, title: "2lamp", want: false},
+		{term: Term{Text: "case"}, title: "Showcase with a case", want: true},
+		{term: Term{Text: "pyrex bowl", Phrase: true}, title: "Vintage Pyrex Bowl", want: true},
+		{term: Term{Text: "pyrex bowl", Phrase: true}, title: "Pyrex mixing bowl", want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.term.Text+"/"+tt.title, func(t *testing.T) {
+			if got := tt.term.Match(gw.Item{Title: tt.title}); got != tt.want {
+				t.Errorf("Match(%q) = %v, want %v", tt.title, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestTighten(t *testing.T) {
+	tests := []struct {
+		in  string
+		min *int64
+		max *int64
+	}{
+		{in: "lamp"},
+		{in: "lamp price:<40", max: ptr(40)},
+		{in: "lamp price:<=40", max: ptr(40)},
+		{in: "lamp price:>10", min: ptr(10)},
+		{in: "lamp price:10-40", min: ptr(10), max: ptr(40)},
+		{in: "lamp price:<40 price:<30", max: ptr(30)},
+		{in: "lamp price:<30 price:<40", max: ptr(30)},
+		{in: "lamp (price:<40 brass)", max: ptr(40)},
+		// fractions round outwards, so items at the bound are still searched for
+		{in: "lamp price:<40.5", max: ptr(41)},
+		{in: "lamp price:>9.5", min: ptr(9)},
+		// prices that don't always have to match can't narrow the search
+		{in: "lamp (price:<10 OR price:>100)"},
+		{in: "lamp (price:<10 OR brass)"},
+		{in: "lamp -price:<10"},
+		{in: "lamp -(price:10-40)"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.in, func(t *testing.T) {
+			q, err := Parse(tt.in)
+			if err != nil && !errors.Is(err, ErrNoSearchText) {
+				t.Fatal(err)
+			}
+			if q == nil {
+				// no search text, so the query is never sent anyway
+				return
+			}
+
+			if !equal(q.MinPrice, tt.min) {
+				t.Errorf("MinPrice = %v, want %v", deref(q.MinPrice), deref(tt.min))
+			}
+
+			if !equal(q.MaxPrice, tt.max) {
+				t.Errorf("MaxPrice = %v, want %v", deref(q.MaxPrice), deref(tt.max))
+			}
+		})
+	}
+}
+
+// TestTightenKeepsMatches checks that items matching the query are never outside the price range searched for.
+func TestTightenKeepsMatches(t *testing.T) {
+	queries := []string{
+		"lamp price:<40",
+		"lamp price:<=40.25",
+		"lamp price:>9.99",
+		"lamp price:10-40",
+		"lamp (price:<10 OR price:>100)",
+		"lamp -price:<10",
+		"lamp (brass price:<20 OR chrome)",
+	}
+
+	for _, in := range queries {
+		t.Run(in, func(t *testing.T) {
+			q, err := Parse(in)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			for cents := int64(0); cents <= 20000; cents += 25 {
+				for _, title := range []string{"brass lamp", "chrome lamp"} {
+					item := gw.Item{Title: title, CurrentPrice: float64(cents) / 100}
+					if !q.Match(item) {
+						continue
+					}
+
+					if q.MinPrice != nil && item.CurrentPrice < float64(*q.MinPrice) {
+						t.Fatalf("%q at $%.2f matches, but the search starts at $%d", title, item.CurrentPrice, *q.MinPrice)
+					}
+
+					if q.MaxPrice != nil && item.CurrentPrice > float64(*q.MaxPrice) {
+						t.Fatalf("%q at $%.2f matches, but the search stops at $%d", title, item.CurrentPrice, *q.MaxPrice)
+					}
+				}
+			}
+		})
+	}
+}
+
+func TestMayMatch(t *testing.T) {
+	soon := gw.Item{Title: "pyrex bowl", CurrentPrice: 20, EndTime: time.Now().Add(time.Hour)}
+	later := gw.Item{Title: "pyrex bowl", CurrentPrice: 20, EndTime: time.Now().Add(48 * time.Hour)}
+
+	tests := []struct {
+		in       string
+		item     gw.Item
+		match    bool
+		mayMatch bool
+	}{
+		{in: "pyrex ends:<2h", item: soon, match: true, mayMatch: true},
+		{in: "pyrex ends:<2h", item: later, match: false, mayMatch: true},
+		{in: "pyrex ends:>1d", item: later, match: true, mayMatch: true},
+		{in: "pyrex -ends:<2h", item: later, match: true, mayMatch: true},
+		// conditions that can't change as the item ends still rule it out
+		{in: "pyrex ends:<2h price:<10", item: later, match: false, mayMatch: false},
+		{in: "pyrex ends:<2h -bowl", item: later, match: false, mayMatch: false},
+		{in: "pyrex (ends:<2h OR price:<10)", item: later, match: false, mayMatch: true},
+		{in: "pyrex (ends:<2h OR bowl)", item: later, match: true, mayMatch: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.in, func(t *testing.T) {
+			q, err := Parse(tt.in)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if got := q.Match(tt.item); got != tt.match {
+				t.Errorf("Match() = %v, want %v", got, tt.match)
+			}
+
+			if got := q.MayMatch(tt.item); got != tt.mayMatch {
+				t.Errorf("MayMatch() = %v, want %v", got, tt.mayMatch)
+			}
+		})
+	}
+}
+
+func TestStringRoundTrip(t *testing.T) {
+	queries := []string{
+		"pyrex bowl",
+		`"pyrex bowl" (blue OR green) -chipped price:<40 ends:<2h`,
+		"a b OR c",
+		"a (b OR (c d)) -e",
+		"lamp -(brass OR chrome) -(-shade)",
+		"lamp price:10-40.5 ends:>1w2d",
+		"lamp -price:10-40",
+		"t-shirt (xl OR \"extra large\")",
+	}
+
+	for _, in := range queries {
+		t.Run(in, func(t *testing.T) {
+			q := parse(t, in)
+			if got := parse(t, q.String()).String(); got != q.String() {
+				t.Errorf("String() = %q, parsed again = %q", q.String(), got)
+			}
+		})
+	}
+}
+
+// parse parses the query like Parse, but also returns queries without search text to check how they match.
+func parse(t *testing.T, in string) *Query {
+	t.Helper()
+
+	tokens, err := tokenize(in)
+	if err != nil {
+		t.Fatalf("tokenize(%q) error = %v", in, err)
+	}
+
+	root, err := (&parser{tokens: tokens}).parse()
+	if err != nil {
+		t.Fatalf("parse(%q) error = %v", in, err)
+	}
+
+	return &Query{root: root}
+}
+
+func ptr(n int64) *int64 {
+	return &n
+}
+
+func equal(a, b *int64) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return *a == *b
+}
+
+func deref(n *int64) any {
+	if n == nil {
+		return nil
+	}
+	return *n
+}
@@ -0,0 +1,211 @@
+package tracker
+
+import (
+	"context"
+	"iter"
+	"strings"
+	"sync"
+
+	"github.com/robherley/gw-bot/internal/db/sqlgen"
+	"github.com/robherley/gw-bot/internal/gw"
+	"github.com/robherley/gw-bot/internal/query"
+)
+
+// Group is subscriptions that send the same search to ShopGoodwill, apart from their price ranges. The group searches
+// once with the widest price range, and each subscription only gets the items matching its own.
+type Group struct {
+	Subscriptions []sqlgen.Subscription
+
+	text          string
+	categoryID    *int64
+	categoryLevel int64
+	minPrice      *int64
+	maxPrice      *int64
+
+	mu    sync.Mutex
+	pages [][]gw.Item
+	err   error
+	done  bool
+	next  func() ([]gw.Item, error, bool)
+	stop  func()
+}
+
+type groupKey struct {
+	text          string
+	categoryID    int64
+	categoryLevel int64
+}
+
+// Group groups the subscriptions that can share a search, in the order they were given. Groups have to be closed
+// once they're no longer used.
+func (t *Tracker) Group(ctx context.Context, subs []sqlgen.Subscription, opts ...gw.SearchOption) []*Group {
+	groups := make([]*Group, 0)
+	byKey := map[groupKey]*Group{}
+
+	for _, sub := range subs {
+		q := Query(sub)
+		min, max := priceRange(sub, q)
+
+		key := groupKey{
+			text:          strings.Join(strings.Fields(strings.ToLower(q.Text)), " "),
+			categoryLevel: 1,
+		}
+		if sub.CategoryID != nil {
+			key.categoryID = *sub.CategoryID
+			if sub.CategoryLevel != nil {
+				key.categoryLevel = *sub.CategoryLevel
+			}
+		}
+
+		group, ok := byKey[key]
+		if !ok {
+			group = &Group{
+				text:          key.text,
+				categoryID:    sub.CategoryID,
+				categoryLevel: key.categoryLevel,
+				minPrice:      min,
+				maxPrice:      max,
+			}
+			byKey[key] = group
+			groups = append(groups, group)
+		} else {
+			group.widen(min, max)
+		}
+
+		group.Subscriptions = append(group.Subscriptions, sub)
+	}
+
+	for _, group := range groups {
+		group.next, group.stop = iter.Pull2(t.gw.Pages(ctx, group.text, t.maxPages, append(group.searchOptions(), opts...)...))
+	}
+
+	return groups
+}
+
+// Pages walks the group's pages of results for one of its subscriptions, like Tracker.Pages. Pages are only
+// searched once, no matter how many subscriptions walk them.
+func (g *Group) Pages(sub sqlgen.Subscription) iter.Seq2[[]gw.Item, error] {
+	return func(yield func([]gw.Item, error) bool) {
+		q := Query(sub)
+		min, max := priceRange(sub, q)
+
+		for i := 0; ; i++ {
+			items, err, ok := g.page(i)
+			if !ok {
+				return
+			}
+
+			if err != nil {
+				yield(nil, err)
+				return
+			}
+
+			matched := make([]gw.Item, 0, len(items))
+			for _, item := range match(sub, q, items, (*query.Query).MayMatch) {
+				if g.inPriceRange(item, min, max) {
+					matched = append(matched, item)
+				}
+			}
+
+			if !yield(matched, nil) {
+				return
+			}
+		}
+	}
+}
+
+func (g *Group) Close() {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	if g.stop != nil {
+		g.stop()
+	}
+}
+
+// page returns the i-th page of results, searching for it if no subscription got that far yet.
+func (g *Group) page(i int) ([]gw.Item, error, bool) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	for len(g.pages) <= i {
+		if g.done {
+			return nil, nil, false
+		}
+
+		if g.err != nil {
+			return nil, g.err, true
+		}
+
+		items, err, ok := g.next()
+		if !ok {
+			g.done = true
+			continue
+		}
+
+		if err != nil {
+			g.err = err
+			continue
+		}
+
+		g.pages = append(g.pages, items)
+	}
+
+	return g.pages[i], nil, true
+}
+
+func (g *Group) searchOptions() []gw.SearchOption {
+	opts := make([]gw.SearchOption, 0)
+	if g.minPrice != nil {
+		opts = append(opts, gw.WithMinPrice(*g.minPrice))
+	}
+
+	if g.maxPrice != nil {
+		opts = append(opts, gw.WithMaxPrice(*g.maxPrice))
+	}
+
+	if g.categoryID != nil {
+		opts = append(opts, gw.WithCategory(*g.categoryID, g.categoryLevel))
+	}
+
+	return opts
+}
+
+// widen grows the group's price range to include min and max, nil being unbounded.
+func (g *Group) widen(min, max *int64) {
+	if min == nil || (g.minPrice != nil && *min < *g.minPrice) {
+		g.minPrice = min
+	}
+
+	if max == nil || (g.maxPrice != nil && *max > *g.maxPrice) {
+		g.maxPrice = max
+	}
+}
+
+// inPriceRange checks the bounds of a subscription that are tighter than the group's, ShopGoodwill already checked
+// the rest.
+func (g *Group) inPriceRange(item gw.Item, min, max *int64) bool {
+	if min != nil && (g.minPrice == nil || *min > *g.minPrice) && item.CurrentPrice < float64(*min) {
+		return false
+	}
+
+	if max != nil && (g.maxPrice == nil || *max < *g.maxPrice) && item.CurrentPrice > float64(*max) {
+		return false
+	}
+
+	return true
+}
+
+// priceRange is the price range searched for a subscription, the tighter of its own and the query's.
+func priceRange(sub sqlgen.Subscription, q *query.Query) (*int64, *int64) {
+	min, max := sub.MinPrice, sub.MaxPrice
+	if q.MinPrice != nil && (min == nil || *q.MinPrice > *min) {
+		min = q.MinPrice
+	}
+
+	if q.MaxPrice != nil && (max == nil || *q.MaxPrice < *max) {
+		max = q.MaxPrice
+	}
+
+	return min, max
+}
@@ -0,0 +1,237 @@
+package tracker
+
+import (
+	"context"
+	"iter"
+	"log/slog"
+	"slices"
+
+	"github.com/robherley/gw-bot/internal/db"
+	"github.com/robherley/gw-bot/internal/db/sqlgen"
+	"github.com/robherley/gw-bot/internal/gw"
+	"github.com/robherley/gw-bot/internal/query"
+)
+
+// MaxTrackedLookup is the most items looked up at once by Tracked, to stay well under SQLite's limit on variables.
+const MaxTrackedLookup = 500
+
+// Searcher searches ShopGoodwill, it's a *gw.Client outside of tests.
+type Searcher interface {
+	Search(ctx context.Context, term string, opts ...gw.SearchOption) ([]gw.Item, error)
+	Pages(ctx context.Context, term string, maxPages int, opts ...gw.SearchOption) iter.Seq2[[]gw.Item, error]
+}
+
+// Tracker records which items have already been seen for a subscription.
+type Tracker struct {
+	db db.DB
+	gw Searcher
+	// maxPages caps how many pages are searched when looking for new items.
+	maxPages int
+}
+
+func New(db db.DB, gw Searcher, maxPages int) *Tracker {
+	return &Tracker{db, gw, maxPages}
+}
+
+// Search finds the items listed for a subscription, leaving out any that don't match its query or filters.
+func (t *Tracker) Search(ctx context.Context, sub sqlgen.Subscription, opts ...gw.SearchOption) ([]gw.Item, error) {
+	return t.search(ctx, sub, (*query.Query).Match, opts...)
+}
+
+func (t *Tracker) search(ctx context.Context, sub sqlgen.Subscription, matches matcher, opts ...gw.SearchOption) ([]gw.Item, error) {
+	q := Query(sub)
+
+	items, err := t.gw.Search(ctx, q.Text, append(searchOptions(sub, q), opts...)...)
+	if err != nil {
+		return nil, err
+	}
+
+	return match(sub, q, items, matches), nil
+}
+
+// Pages is like Search, but walks up to the tracker's max pages of results to track new items. Items that could match
+// once they're closer to ending are kept, see query.Query.MayMatch. Pages can be empty if none of their items matched.
+func (t *Tracker) Pages(ctx context.Context, sub sqlgen.Subscription, opts ...gw.SearchOption) iter.Seq2[[]gw.Item, error] {
+	return func(yield func([]gw.Item, error) bool) {
+		q := Query(sub)
+
+		for items, err := range t.gw.Pages(ctx, q.Text, t.maxPages, append(searchOptions(sub, q), opts...)...) {
+			if err != nil {
+				yield(nil, err)
+				return
+			}
+
+			if !yield(match(sub, q, items, (*query.Query).MayMatch), nil) {
+				return
+			}
+		}
+	}
+}
+
+func searchOptions(sub sqlgen.Subscription, q *query.Query) []gw.SearchOption {
+	opts := gw.SearchOptionsFromSubscription(sub)
+	if q.MinPrice != nil && (sub.MinPrice == nil || *q.MinPrice > *sub.MinPrice) {
+		opts = append(opts, gw.WithMinPrice(*q.MinPrice))
+	}
+
+	if q.MaxPrice != nil && (sub.MaxPrice == nil || *q.MaxPrice < *sub.MaxPrice) {
+		opts = append(opts, gw.WithMaxPrice(*q.MaxPrice))
+	}
+
+	return opts
+}
+
+// matcher is how items are matched against a query, like query.Query.Match.
+type matcher func(q *query.Query, item gw.Item) bool
+
+func match(sub sqlgen.Subscription, q *query.Query, items []gw.Item, matches matcher) []gw.Item {
+	filter := gw.FilterFromSubscription(sub)
+
+	matched := make([]gw.Item, 0, len(items))
+	for _, item := range items {
+		if matches(q, item) && filter.Match(item) {
+			matched = append(matched, item)
+		}
+	}
+
+	return matched
+}
+
+// Query parses the term of a subscription. Terms that don't parse, from before they were validated, are searched as is.
+func Query(sub sqlgen.Subscription) *query.Query {
+	q, err := query.Parse(sub.Term)
+	if err != nil {
+		slog.Warn("failed to parse subscription term", "subscription_id", sub.ID, "error", err)
+		return query.Plain(sub.Term)
+	}
+	return q
+}
+
+// Seed tracks the items currently listed for a subscription, so only items listed afterwards are notified.
+func (t *Tracker) Seed(ctx context.Context, sub sqlgen.Subscription) (int, error) {
+	items := map[int64]gw.Item{}
+
+	for newestItems, err := range t.Pages(ctx, sub, gw.WithDescending(true)) {
+		if err != nil {
+			return 0, err
+		}
+
+		for _, item := range newestItems {
+			items[item.ItemID] = item
+		}
+	}
+
+	endingSoonItems, err := t.search(ctx, sub, (*query.Query).MayMatch, gw.WithDescending(false))
+	if err != nil {
+		return 0, err
+	}
+
+	for _, item := range endingSoonItems {
+		items[item.ItemID] = item
+	}
+
+	listed := make([]gw.Item, 0, len(items))
+	for _, item := range items {
+		if !item.Ended() {
+			listed = append(listed, item)
+		}
+	}
+
+	tracked, err := t.Tracked(ctx, sub.ID, listed)
+	if err != nil {
+		return 0, err
+	}
+
+	untracked := make([]gw.Item, 0, len(listed))
+	for _, item := range listed {
+		if !tracked[item.ItemID] {
+			untracked = append(untracked, item)
+		}
+	}
+
+	if err := t.Track(ctx, sub, untracked); err != nil {
+		return 0, err
+	}
+
+	return len(untracked), nil
+}
+
+// Tracked returns which of the items are already tracked for the subscription.
+func (t *Tracker) Tracked(ctx context.Context, subscriptionID string, items []gw.Item) (map[int64]bool, error) {
+	tracked := make(map[int64]bool)
+
+	for chunk := range slices.Chunk(items, MaxTrackedLookup) {
+		goodwillIDs := make([]int64, 0, len(chunk))
+		for _, item := range chunk {
+			goodwillIDs = append(goodwillIDs, item.ItemID)
+		}
+
+		ids, err := t.db.FindTrackedGoodwillIDs(ctx, sqlgen.FindTrackedGoodwillIDsParams{
+			SubscriptionID: subscriptionID,
+			GoodwillIds:    goodwillIDs,
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		for _, id := range ids {
+			tracked[id] = true
+		}
+	}
+
+	return tracked, nil
+}
+
+// Track starts tracking the items for the subscription, all of them or none.
+func (t *Tracker) Track(ctx context.Context, sub sqlgen.Subscription, items []gw.Item) error {
+	if len(items) == 0 {
+		return nil
+	}
+
+	return t.db.WithTx(ctx, func(q sqlgen.Querier) error {
+		return CreateItems(ctx, q, sub, items)
+	})
+}
+
+// CreateItems tracks the items for the subscription with q, for tracking items in a bigger transaction.
+func CreateItems(ctx context.Context, q sqlgen.Querier, sub sqlgen.Subscription, items []gw.Item) error {
+	for _, item := range items {
+		if _, err := q.CreateItem(ctx, item.NewCreateItemParams(sub)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Resume unpauses a subscription. Anything listed while it was paused is tracked without being notified.
+func (t *Tracker) Resume(ctx context.Context, sub sqlgen.Subscription) (int, error) {
+	n, err := t.Seed(ctx, sub)
+	if err != nil {
+		return n, err
+	}
+
+	return n, t.db.ResumeSubscription(ctx, sub.ID)
+}
+
+// EndVacation resumes all of a user's subscriptions that are not paused on their own.
+func (t *Tracker) EndVacation(ctx context.Context, userID string) (int, error) {
+	subs, err := t.db.FindUserSubscriptions(ctx, userID)
+	if err != nil {
+		return 0, err
+	}
+
+	n := 0
+	for _, sub := range subs {
+		if sub.PausedAt != nil {
+			continue
+		}
+
+		seeded, err := t.Seed(ctx, sub)
+		n += seeded
+		if err != nil {
+			return n, err
+		}
+	}
+
+	return n, t.db.EndUserVacation(ctx, userID)
+}
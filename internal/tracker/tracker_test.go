@@ -0,0 +1,116 @@
+package tracker
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/pressly/goose/v3"
+	"github.com/robherley/gw-bot/internal/db"
+	"github.com/robherley/gw-bot/internal/db/sqlgen"
+	"github.com/robherley/gw-bot/internal/gw"
+)
+
+// benchTracked is how many items are already tracked for the subscription in BenchmarkFindNewItems.
+const benchTracked = 5000
+
+// BenchmarkFindNewItems compares looking up and tracking a page of items one at a time, like the looper used to,
+// with Tracked and Track. Half of each page is already tracked.
+func BenchmarkFindNewItems(b *testing.B) {
+	for _, size := range []int{40, 200} {
+		b.Run("per item/"+strconv.Itoa(size), func(b *testing.B) {
+			ctx, sqlite, sub, page := setupBench(b, size)
+
+			for range b.N {
+				for _, item := range page() {
+					var tracked bool
+					if err := sqlite.QueryRowContext(ctx, "SELECT EXISTS (SELECT 1 FROM items WHERE subscription_id = ? AND goodwill_id = ?)", sub.ID, item.ItemID).Scan(&tracked); err != nil {
+						b.Fatal(err)
+					}
+
+					if tracked {
+						continue
+					}
+
+					if _, err := sqlite.CreateItem(ctx, item.NewCreateItemParams(sub)); err != nil {
+						b.Fatal(err)
+					}
+				}
+			}
+		})
+
+		b.Run("batched/"+strconv.Itoa(size), func(b *testing.B) {
+			ctx, sqlite, sub, page := setupBench(b, size)
+			t := New(sqlite, nil, 1)
+
+			for range b.N {
+				items := page()
+
+				tracked, err := t.Tracked(ctx, sub.ID, items)
+				if err != nil {
+					b.Fatal(err)
+				}
+
+				untracked := make([]gw.Item, 0, len(items))
+				for _, item := range items {
+					if !tracked[item.ItemID] {
+						untracked = append(untracked, item)
+					}
+				}
+
+				if err := t.Track(ctx, sub, untracked); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
+
+// setupBench returns a temp database with benchTracked items tracked for a subscription, and a func returning the
+// next page of size items, half of them untracked.
+func setupBench(b *testing.B, size int) (context.Context, *db.SQLite, sqlgen.Subscription, func() []gw.Item) {
+	b.Helper()
+	ctx := context.Background()
+
+	d, err := db.NewSQLite(filepath.Join(b.TempDir(), "bench.db"))
+	if err != nil {
+		b.Fatal(err)
+	}
+	sqlite := d.(*db.SQLite)
+	b.Cleanup(func() { sqlite.Close() })
+
+	goose.SetLogger(goose.NopLogger())
+	if err := sqlite.Migrate(ctx, os.DirFS("../..")); err != nil {
+		b.Fatal(err)
+	}
+
+	sub := sqlgen.Subscription{ID: db.NewID(), UserID: "user"}
+	item := func(id int64) gw.Item {
+		return gw.Item{ItemID: id, StartTime: time.Now(), EndTime: time.Now().Add(24 * time.Hour)}
+	}
+
+	seeded := make([]gw.Item, 0, benchTracked)
+	for id := range int64(benchTracked) {
+		seeded = append(seeded, item(id))
+	}
+
+	if err := New(sqlite, nil, 1).Track(ctx, sub, seeded); err != nil {
+		b.Fatal(err)
+	}
+
+	next := int64(benchTracked)
+	page := func() []gw.Item {
+		items := make([]gw.Item, 0, size)
+		for i := range int64(size / 2) {
+			items = append(items, item(next), item(i))
+			next++
+		}
+		return items
+	}
+
+	b.ResetTimer()
+	return ctx, sqlite, sub, page
+}
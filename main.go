@@ -17,6 +17,8 @@ import (
 	"github.com/robherley/gw-bot/internal/db"
 	"github.com/robherley/gw-bot/internal/gw"
 	"github.com/robherley/gw-bot/internal/looper"
+	"github.com/robherley/gw-bot/internal/notify"
+	"github.com/robherley/gw-bot/internal/tracker"
 )
 
 //go:embed database/migrations/*.sql
@@ -25,6 +27,23 @@ var migrations embed.FS
 type Config struct {
 	DiscordToken string `desc:"API Token for Discord" required:"true"`
 	DatabaseFile string `desc:"Path of SQLite database file" default:"gw-bot.db" required:"false"`
+	SMTPHost     string `desc:"SMTP server to send email alerts with, email is disabled if not set" required:"false"`
+	SMTPPort     int    `desc:"Port of the SMTP server, STARTTLS is used if supported" default:"587" required:"false"`
+	SMTPUsername string `desc:"Username for SMTP PLAIN auth, no auth if not set" required:"false"`
+	SMTPPassword string `desc:"Password for SMTP PLAIN auth" required:"false"`
+	SMTPFrom     string `desc:"Address email alerts are sent from" default:"gw-bot@localhost" required:"false"`
+	MaxPages     int    `desc:"Most pages of search results to look through for new items" default:"5" required:"false"`
+	Workers      int    `desc:"How many searches for new items run at once" default:"4" required:"false"`
+
+	MinPollInterval time.Duration `desc:"Shortest time between searches for new items of a subscription, used for priority subscriptions" default:"2m" required:"false"`
+	MaxPollInterval time.Duration `desc:"Longest time between searches for new items of a subscription that rarely finds any" default:"30m" required:"false"`
+
+	GWTimeout          time.Duration `desc:"Timeout of each request to ShopGoodwill" default:"15s" required:"false"`
+	GWRetries          int           `desc:"How many times failed requests to ShopGoodwill are retried" default:"3" required:"false"`
+	GWBreakerThreshold int           `desc:"How many requests to ShopGoodwill can fail in a row before requests are paused" default:"5" required:"false"`
+	GWBreakerCooldown  time.Duration `desc:"How long requests to ShopGoodwill are paused for after too many failed" default:"1m" required:"false"`
+	GWRateLimit        float64       `desc:"Requests per second made to ShopGoodwill, 0 for no limit" default:"0.5" required:"false"`
+	GWRateBurst        int           `desc:"How many requests to ShopGoodwill can be made at once before being rate limited" default:"5" required:"false"`
 }
 
 func init() {
@@ -74,9 +93,34 @@ func run() error {
 		return err
 	}
 
-	gw := gw.New()
+	gw := gw.New(
+		gw.WithTimeout(cfg.GWTimeout),
+		gw.WithRetries(cfg.GWRetries),
+		gw.WithBreaker(cfg.GWBreakerThreshold, cfg.GWBreakerCooldown),
+		gw.WithRateLimit(cfg.GWRateLimit, cfg.GWRateBurst),
+	)
+	tracker := tracker.New(db, gw, cfg.MaxPages)
+
+	// autocomplete can't wait on fetching the categories
+	go func() {
+		if _, err := gw.Categories(ctx); err != nil {
+			slog.Warn("failed to fetch categories", "error", err)
+		}
+	}()
+	webhook := notify.NewWebhook(db)
+
+	var email *notify.Email
+	if cfg.SMTPHost != "" {
+		email = notify.NewEmail(db, notify.SMTPConfig{
+			Host:     cfg.SMTPHost,
+			Port:     cfg.SMTPPort,
+			Username: cfg.SMTPUsername,
+			Password: cfg.SMTPPassword,
+			From:     cfg.SMTPFrom,
+		})
+	}
 
-	bot, err := bot.New(ctx, cfg.DiscordToken, db, gw)
+	bot, err := bot.New(ctx, cfg.DiscordToken, db, gw, tracker, webhook, email)
 	if err != nil {
 		return err
 	}
@@ -108,10 +152,27 @@ func run() error {
 
 	slog.Info("github.com/robherley/gw-bot is initialized")
 
-	l := looper.New(db, bot, gw)
+	sinks := []notify.Sink{
+		{Name: "discord", Notifier: bot},
+		{Name: "webhook", Notifier: webhook},
+	}
+	if email != nil {
+		sinks = append(sinks, notify.Sink{Name: "email", Notifier: email})
+	}
+
+	l := looper.New(db, gw, tracker, cfg.Workers, looper.PollIntervals{
+		Min: cfg.MinPollInterval,
+		Max: cfg.MaxPollInterval,
+	}, sinks...)
 	go l.Cleanup(ctx)
+	go l.Resume(ctx)
 	go l.NotifyEndingSoonItems(ctx)
 	go l.NotifyNewItems(ctx)
+	go l.DispatchOutbox(ctx)
+	go l.LogWaitStats(ctx)
+	go l.SendDigests(ctx)
+	go l.RefreshItems(ctx)
+	go l.RecordResults(ctx)
 
 	wait()
 	return nil